@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
@@ -43,23 +45,105 @@ func main() {
 
 	// Services
 	questionGenerator := services.NewQuestionGeneratorService(database.DB, cfg)
-	authService := services.NewAuthService(database.DB, cfg)
-	subscriptionService := services.NewSubscriptionService(database.DB)
-	moderationService := services.NewModerationService(database.DB)
-	challengeService := services.NewChallengeService(database.DB, questionGenerator)
+	mailer, err := services.NewMailer(cfg)
+	if err != nil {
+		log.Fatalf("Mailer error: %v", err)
+	}
+	emailService := services.NewEmailService(database.DB, cfg, mailer)
+	subscriptionService := services.NewSubscriptionService(database.DB, cfg)
+	pushProviders, err := services.NewPushProviders(cfg)
+	if err != nil {
+		log.Fatalf("Push provider error: %v", err)
+	}
+	pushService, err := services.NewPushService(database.DB, cfg, pushProviders)
+	if err != nil {
+		log.Fatalf("Push configuration error: %v", err)
+	}
+	notificationService := services.NewNotificationService(database.DB, cfg, services.NewLogNotifier(), pushService)
+	notifier := notificationService
+	moderationService := services.NewModerationService(database.DB, notifier)
+	statsService := services.NewStatsService(database.DB)
+	achievementService := services.NewAchievementService(database.DB, statsService, subscriptionService, notifier)
+	if err := achievementService.SeedDefaults(); err != nil {
+		log.Fatalf("Achievement seeding failed: %v", err)
+	}
+	blobStorage, err := services.NewLocalBlobStorage(cfg)
+	if err != nil {
+		log.Fatalf("Media storage error: %v", err)
+	}
+	userService := services.NewUserService(database.DB, moderationService, blobStorage)
+	progressionService, err := services.NewProgressionService(database.DB, cfg, subscriptionService, userService, notifier)
+	if err != nil {
+		log.Fatalf("Season configuration error: %v", err)
+	}
+	challengeService := services.NewChallengeService(database.DB, questionGenerator, subscriptionService, achievementService, progressionService)
+	authService := services.NewAuthService(database.DB, cfg, emailService, challengeService)
+	feedService := services.NewFeedService(database.DB, challengeService, cfg)
+	packSigner, err := services.NewPackSigner(cfg)
+	if err != nil {
+		log.Fatalf("Pack signing key error: %v", err)
+	}
+	packService := services.NewPackService(database.DB, subscriptionService, packSigner)
+	shareCardRenderer, err := services.NewShareCardRenderer()
+	if err != nil {
+		log.Fatalf("Share card renderer error: %v", err)
+	}
+	shareService := services.NewShareService(database.DB, cfg, shareCardRenderer, achievementService, progressionService)
+	personalityService := services.NewPersonalityService(database.DB, questionGenerator, moderationService, statsService)
+	exportService := services.NewExportService(database.DB, cfg, blobStorage, notifier)
+	friendService := services.NewFriendService(database.DB, userService, notifier)
+	deletionService := services.NewDeletionService(database.DB, cfg, blobStorage)
+	landingService := services.NewLandingService(database.DB, cfg, challengeService, feedService, shareService, shareCardRenderer)
 
 	// Handlers
 	authHandler := handlers.NewAuthHandler(authService)
+	emailHandler := handlers.NewEmailHandler(emailService)
 	healthHandler := handlers.NewHealthHandler()
 	webhookHandler := handlers.NewWebhookHandler(subscriptionService, cfg)
 	moderationHandler := handlers.NewModerationHandler(moderationService)
-	challengeHandler := handlers.NewChallengeHandler(challengeService, questionGenerator)
+	challengeHandler := handlers.NewChallengeHandler(challengeService, questionGenerator, subscriptionService, friendService)
 	legalHandler := handlers.NewLegalHandler()
+	feedHandler := handlers.NewFeedHandler(feedService)
+	packHandler := handlers.NewPackHandler(packService, packSigner)
+	shareHandler := handlers.NewShareHandler(shareService)
+	landingHandler := handlers.NewLandingHandler(landingService)
+	userHandler := handlers.NewUserHandler(userService, statsService, personalityService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	friendHandler := handlers.NewFriendHandler(friendService)
+	achievementHandler := handlers.NewAchievementHandler(achievementService)
+	progressionHandler := handlers.NewProgressionHandler(progressionService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	deviceHandler := handlers.NewDeviceHandler(pushService)
+
+	// Background jobs
+	scheduler := services.NewScheduler()
+	scheduler.Every("data-export-process", time.Minute, exportService.ProcessPending)
+	scheduler.Every("data-export-cleanup", time.Hour, exportService.CleanupExpired)
+	scheduler.Every("account-purge", time.Hour, deletionService.PurgeDue)
+	scheduler.Every("season-rollover", time.Hour, progressionService.FinalizeSeasons)
+	scheduler.Every("notification-cleanup", time.Hour, notificationService.CleanupExpired)
+	scheduler.Every("daily-push", 10*time.Minute, func(ctx context.Context) error {
+		daily, err := challengeService.GetDailyChallenge()
+		if err != nil {
+			return err
+		}
+		return pushService.SendDailyReminders(ctx, daily)
+	})
+	scheduler.Every("push-receipts", 15*time.Minute, pushService.CheckReceipts)
 
 	// Fiber app
+	// Behind a reverse proxy, c.IP() (rate limits, guest caps) must come
+	// from the proxy's header, and only when the proxy itself sent it
+	if cfg.ProxyHeader != "" && len(cfg.TrustedProxyList()) == 0 {
+		log.Fatal("TRUSTED_PROXIES is required when PROXY_HEADER is set")
+	}
 	app := fiber.New(fiber.Config{
-		BodyLimit:    4 * 1024 * 1024, // 4MB
-		ErrorHandler: customErrorHandler,
+		BodyLimit:               4 * 1024 * 1024, // 4MB
+		ErrorHandler:            customErrorHandler,
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: cfg.ProxyHeader != "",
+		TrustedProxies:          cfg.TrustedProxyList(),
+		EnableIPValidation:      true,
 	})
 
 	// Global middleware
@@ -78,8 +162,12 @@ func main() {
 	})
 	app.Use("/api/auth", authLimiter)
 
+	// Uploaded media (local blob storage). Only avatars are public; data
+	// exports share the store but are served through signed URLs.
+	app.Static("/media/avatars", filepath.Join(blobStorage.Dir(), "avatars"), fiber.Static{MaxAge: 86400})
+
 	// Routes
-	routes.Setup(app, cfg, database.DB, authHandler, healthHandler, webhookHandler, moderationHandler, challengeHandler, legalHandler)
+	routes.Setup(app, cfg, database.DB, authHandler, emailHandler, healthHandler, webhookHandler, moderationHandler, challengeHandler, legalHandler, feedHandler, packHandler, shareHandler, landingHandler, userHandler, exportHandler, friendHandler, achievementHandler, progressionHandler, notificationHandler, deviceHandler, subscriptionService, packService)
 
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -91,11 +179,14 @@ func main() {
 		}
 	}()
 
+	scheduler.Start()
+
 	log.Printf("Server running on port %s", cfg.Port)
 	log.Printf("AI Generation: %v", questionGenerator.IsAvailable())
 
 	<-quit
 	log.Println("Shutting down server...")
+	scheduler.Stop()
 	if err := app.Shutdown(); err != nil {
 		log.Fatalf("Server shutdown error: %v", err)
 	}
@@ -3,10 +3,14 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
+	AppEnv string // "development" relaxes production-only requirements
+
 	DBHost     string
 	DBPort     string
 	DBUser     string
@@ -17,20 +21,66 @@ type Config struct {
 	JWTSecret        string
 	JWTAccessExpiry  time.Duration
 	JWTRefreshExpiry time.Duration
+	GuestTokenExpiry time.Duration
+	AllowRawGuestIDs bool // Accept unsigned "Guest <device-id>" headers while old clients update
+	GuestTokensPerIP int  // New guest identities per client IP per day; 0 disables the cap
 
 	RevenueCatWebhookAuth string
 	AppleBundleID         string
+	PremiumEntitlementID  string
+	PremiumCategories     string
+
+	Port           string
+	CORSOrigins    string
+	PublicBaseURL  string
+	ProxyHeader    string // Header carrying the client IP, set by the reverse proxy
+	TrustedProxies string // Comma-separated IPs/CIDRs allowed to set ProxyHeader
+
+	MediaDir     string
+	MediaBaseURL string
+
+	DataExportRetention  time.Duration
+	AccountDeletionGrace time.Duration
+	NotificationTTL      time.Duration
 
-	Port        string
-	CORSOrigins string
+	SeasonTimezone string // Seasons roll over at midnight in this zone
+	SeasonStartDay int    // Day of the month each season starts (1-28)
+	SeasonPrizes   string // Comma-separated "max_rank:entitlement:duration" tiers
+
+	AppStoreURL  string
+	PlayStoreURL string
+	AppleAppID   string
+
+	MailDriver           string
+	MailFrom             string
+	MailDir              string
+	SMTPHost             string
+	SMTPPort             string
+	SMTPUsername         string
+	SMTPPassword         string
+	EmailVerificationTTL time.Duration
 
 	GLMApiURL string
 	GLMApiKey string
 	GLMModel  string
+
+	PackSigningKey  string
+	PackTrustedKeys string
+
+	PushDriver      string
+	ExpoAccessToken string
+	APNsKeyFile     string
+	APNsKeyID       string
+	APNsTeamID      string
+	APNsTopic       string
+	APNsProduction  bool
+	DailyPushHour   int // Local hour the "daily is live" reminder goes out
 }
 
 func Load() *Config {
 	return &Config{
+		AppEnv: getEnv("APP_ENV", "production"),
+
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "5432"),
 		DBUser:     getEnv("DB_USER", "postgres"),
@@ -41,19 +91,68 @@ func Load() *Config {
 		JWTSecret:        getEnv("JWT_SECRET", ""),
 		JWTAccessExpiry:  parseDuration(getEnv("JWT_ACCESS_EXPIRY", "15m")),
 		JWTRefreshExpiry: parseDuration(getEnv("JWT_REFRESH_EXPIRY", "168h")),
+		GuestTokenExpiry: parseDuration(getEnv("GUEST_TOKEN_EXPIRY", "2160h")),
+		AllowRawGuestIDs: getEnv("ALLOW_RAW_GUEST_IDS", "true") == "true",
+		GuestTokensPerIP: parseInt(getEnv("GUEST_TOKENS_PER_IP", "30"), 30),
 
 		RevenueCatWebhookAuth: getEnv("REVENUECAT_WEBHOOK_AUTH", ""),
 		AppleBundleID:         getEnv("APPLE_BUNDLE_ID", ""),
+		PremiumEntitlementID:  getEnv("PREMIUM_ENTITLEMENT_ID", "premium"),
+		PremiumCategories:     getEnv("PREMIUM_CATEGORIES", ""),
+
+		Port:           getEnv("PORT", "8080"),
+		CORSOrigins:    getEnv("CORS_ORIGINS", "*"),
+		PublicBaseURL:  strings.TrimSuffix(getEnv("PUBLIC_BASE_URL", "https://wouldyou.app"), "/"),
+		ProxyHeader:    getEnv("PROXY_HEADER", ""),
+		TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
+
+		MediaDir:     getEnv("MEDIA_DIR", "./media"),
+		MediaBaseURL: strings.TrimSuffix(getEnv("MEDIA_BASE_URL", ""), "/"),
+
+		DataExportRetention:  parseDuration(getEnv("DATA_EXPORT_RETENTION", "72h")),
+		AccountDeletionGrace: parseDuration(getEnv("ACCOUNT_DELETION_GRACE", "720h")),
+		NotificationTTL:      parseDuration(getEnv("NOTIFICATION_TTL", "720h")),
+
+		SeasonTimezone: getEnv("SEASON_TIMEZONE", "UTC"),
+		SeasonStartDay: parseInt(getEnv("SEASON_START_DAY", "1"), 1),
+		SeasonPrizes:   getEnv("SEASON_PRIZES", ""),
 
-		Port:        getEnv("PORT", "8080"),
-		CORSOrigins: getEnv("CORS_ORIGINS", "*"),
+		AppStoreURL:  getEnv("APP_STORE_URL", "https://apps.apple.com/app/wouldyou"),
+		PlayStoreURL: getEnv("PLAY_STORE_URL", "https://play.google.com/store/apps/details?id=com.ahmetcoskunkizilkaya.wouldyou"),
+		AppleAppID:   getEnv("APPLE_APP_ID", ""),
+
+		MailDriver:           getEnv("MAIL_DRIVER", "log"),
+		MailFrom:             getEnv("MAIL_FROM", "WouldYou <no-reply@wouldyou.app>"),
+		MailDir:              getEnv("MAIL_DIR", "./mail"),
+		SMTPHost:             getEnv("SMTP_HOST", ""),
+		SMTPPort:             getEnv("SMTP_PORT", "587"),
+		SMTPUsername:         getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:         getEnv("SMTP_PASSWORD", ""),
+		EmailVerificationTTL: parseDuration(getEnv("EMAIL_VERIFICATION_TTL", "48h")),
 
 		GLMApiURL: getEnv("GLM_API_URL", "https://api.z.ai/api/paas/v4/chat/completions"),
 		GLMApiKey: getEnv("GLM_API_KEY", ""),
 		GLMModel:  getEnv("GLM_MODEL", "glm-5"),
+
+		PackSigningKey:  getEnv("PACK_SIGNING_KEY", ""),
+		PackTrustedKeys: getEnv("PACK_TRUSTED_KEYS", ""),
+
+		PushDriver:      getEnv("PUSH_DRIVER", "log"),
+		ExpoAccessToken: getEnv("EXPO_ACCESS_TOKEN", ""),
+		APNsKeyFile:     getEnv("APNS_KEY_FILE", ""),
+		APNsKeyID:       getEnv("APNS_KEY_ID", ""),
+		APNsTeamID:      getEnv("APNS_TEAM_ID", ""),
+		APNsTopic:       getEnv("APNS_TOPIC", ""),
+		APNsProduction:  getEnv("APNS_PRODUCTION", "false") == "true",
+		DailyPushHour:   parseInt(getEnv("DAILY_PUSH_HOUR", "9"), 9),
 	}
 }
 
+// IsDevelopment reports whether APP_ENV is "development".
+func (c *Config) IsDevelopment() bool {
+	return c.AppEnv == "development"
+}
+
 func (c *Config) Validate() error {
 	if c.GLMApiKey == "" {
 		log.Println("WARNING: GLM_API_KEY not set, AI generation disabled")
@@ -61,6 +160,17 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// TrustedProxyList returns TRUSTED_PROXIES as a list.
+func (c *Config) TrustedProxyList() []string {
+	var proxies []string
+	for _, p := range strings.Split(c.TrustedProxies, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
 func (c *Config) DSN() string {
 	return "host=" + c.DBHost +
 		" user=" + c.DBUser +
@@ -78,6 +188,14 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func parseInt(s string, fallback int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
 func parseDuration(s string) time.Duration {
 	d, err := time.ParseDuration(s)
 	if err != nil {
@@ -35,6 +35,18 @@ func Connect(cfg *config.Config) error {
 }
 
 func Migrate() error {
+	if err := dedupeVotes(DB); err != nil {
+		return fmt.Errorf("failed to remove duplicate votes: %w", err)
+	}
+	// Purged accounts leave shares without an owner, which the old index
+	// allowed only once per challenge; idx_shares_owner_challenge replaces it
+	if DB.Migrator().HasIndex(&models.Share{}, "idx_shares_user_challenge") {
+		if err := DB.Migrator().DropIndex(&models.Share{}, "idx_shares_user_challenge"); err != nil {
+			return fmt.Errorf("failed to drop share index: %w", err)
+		}
+	}
+	backfillInstalls := !DB.Migrator().HasTable(&models.ShareInstall{})
+
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.RefreshToken{},
@@ -44,15 +56,82 @@ func Migrate() error {
 		&models.Challenge{},
 		&models.Vote{},
 		&models.ChallengeStreak{},
+		&models.QuestionPack{},
+		&models.QuestionPackItem{},
+		&models.Share{},
+		&models.ShareEvent{},
+		&models.ShareInstall{},
+		&models.UserStats{},
+		&models.PersonalitySummary{},
+		&models.DataExport{},
+		&models.DeletionReceipt{},
+		&models.EmailToken{},
+		&models.GuestIdentity{},
+		&models.Friendship{},
+		&models.Achievement{},
+		&models.UserAchievement{},
+		&models.AchievementCounter{},
+		&models.XPEvent{},
+		&models.UserXP{},
+		&models.Season{},
+		&models.SeasonScore{},
+		&models.EntitlementGrant{},
+		&models.Notification{},
+		&models.DeviceToken{},
+		&models.PushTicket{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	// Installs used to be attributed on the user row, which a purge deletes
+	if backfillInstalls && DB.Migrator().HasColumn(&models.User{}, "referral_share_code") {
+		if err := DB.Exec(`
+			INSERT INTO share_installs (share_id, user_id, created_at)
+			SELECT shares.id, users.id, users.created_at
+			FROM users JOIN shares ON shares.code = users.referral_share_code`).Error; err != nil {
+			return fmt.Errorf("failed to backfill share installs: %w", err)
+		}
+	}
+
 	log.Println("Database migrations completed")
 	return nil
 }
 
+// dedupeVotes prepares tables from before idx_votes_user_challenge, when
+// votes were only checked before insert and a user could end up with
+// several on one challenge. It keeps each user's oldest vote, deletes the
+// rest and recounts the affected challenges, so creating the unique index
+// can't fail. Stats fold the remaining votes on their next rebuild.
+func dedupeVotes(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.Vote{}) || db.Migrator().HasIndex(&models.Vote{}, "idx_votes_user_challenge") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var challengeIDs []string
+		if err := tx.Raw(`
+			DELETE FROM votes dup USING votes keep
+			WHERE dup.user_id = keep.user_id AND dup.challenge_id = keep.challenge_id
+			AND dup.user_id <> '00000000-0000-0000-0000-000000000000'
+			AND dup.deleted_at IS NULL AND keep.deleted_at IS NULL
+			AND (keep.created_at, keep.id) < (dup.created_at, dup.id)
+			RETURNING dup.challenge_id`).Scan(&challengeIDs).Error; err != nil {
+			return err
+		}
+		if len(challengeIDs) == 0 {
+			return nil
+		}
+
+		log.Printf("Removed %d duplicate votes", len(challengeIDs))
+		return tx.Exec(`
+			UPDATE challenges SET
+				votes_a = (SELECT COUNT(*) FROM votes WHERE votes.challenge_id = challenges.id AND votes.choice = 'A' AND votes.deleted_at IS NULL),
+				votes_b = (SELECT COUNT(*) FROM votes WHERE votes.challenge_id = challenges.id AND votes.choice = 'B' AND votes.deleted_at IS NULL)
+			WHERE id IN ?`, challengeIDs).Error
+	})
+}
+
 func Ping() error {
 	sqlDB, err := DB.DB()
 	if err != nil {
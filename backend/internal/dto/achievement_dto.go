@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+type AchievementResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Icon        string     `json:"icon"`
+	Unlocked    bool       `json:"unlocked"`
+	UnlockedAt  *time.Time `json:"unlocked_at"`
+	Progress    int        `json:"progress"` // Current value, capped at Target
+	Target      int        `json:"target"`
+}
+
+type AchievementsResponse struct {
+	Achievements []AchievementResponse `json:"achievements"`
+	Unlocked     int                   `json:"unlocked"`
+	Total        int                   `json:"total"`
+}
+
+type AchievementRuleRequest struct {
+	Type      string `json:"type"`
+	Threshold int    `json:"threshold"`
+	Event     string `json:"event,omitempty"`
+	Category  string `json:"category,omitempty"`
+	DailyOnly bool   `json:"daily_only,omitempty"`
+}
+
+// UpsertAchievementRequest creates or replaces an achievement definition.
+type UpsertAchievementRequest struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Icon        string                 `json:"icon"`
+	Rule        AchievementRuleRequest `json:"rule"`
+	Active      *bool                  `json:"active"` // Defaults to true
+	SortOrder   int                    `json:"sort_order"`
+}
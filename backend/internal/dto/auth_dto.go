@@ -1,15 +1,25 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	ShareCode  string `json:"share_code,omitempty"`  // Share link that led to the install
+	Locale     string `json:"locale,omitempty"`      // Email language; defaults to Accept-Language
+	GuestID    string `json:"guest_id,omitempty"`    // Raw guest ID (legacy, see ALLOW_RAW_GUEST_IDS)
+	GuestToken string `json:"guest_token,omitempty"` // Signed guest identity whose votes move to the new account
 }
 
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	GuestID    string `json:"guest_id,omitempty"`    // Raw guest ID (legacy, see ALLOW_RAW_GUEST_IDS)
+	GuestToken string `json:"guest_token,omitempty"` // Signed guest identity whose votes move to the account
 }
 
 type RefreshRequest struct {
@@ -21,14 +31,28 @@ type LogoutRequest struct {
 }
 
 type AuthResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	User         UserResponse `json:"user"`
+	AccessToken      string       `json:"access_token"`
+	RefreshToken     string       `json:"refresh_token"`
+	User             UserResponse `json:"user"`
+	AccountRestored  bool         `json:"account_restored,omitempty"`   // Sign-in cancelled a pending account deletion
+	MergedGuestVotes int          `json:"merged_guest_votes,omitempty"` // Guest votes moved to the account
 }
 
 type UserResponse struct {
-	ID    uuid.UUID `json:"id"`
-	Email string    `json:"email"`
+	ID            uuid.UUID `json:"id"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+}
+
+type GuestTokenRequest struct {
+	GuestToken string `json:"guest_token,omitempty"` // Renew an existing (possibly expired) guest token
+	DeviceID   string `json:"device_id,omitempty"`   // Adopt a legacy raw guest ID, while allowed
+}
+
+type GuestTokenResponse struct {
+	GuestToken string    `json:"guest_token"` // Send as "Authorization: Guest <token>"
+	GuestID    string    `json:"guest_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
 }
 
 type ErrorResponse struct {
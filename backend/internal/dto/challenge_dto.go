@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 // ChallengeResponse is the API response for a single challenge
 type ChallengeResponse struct {
 	ID         string  `json:"id"`
@@ -21,3 +27,50 @@ type ChallengeListResponse struct {
 	Data  []ChallengeResponse `json:"data"`
 	Total int                 `json:"total"`
 }
+
+// --- Friends' picks ---
+
+// FriendPick is one friend's choice on a challenge.
+type FriendPick struct {
+	UserID      uuid.UUID `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	Choice      string    `json:"choice"`
+}
+
+// FriendPicksResponse summarizes how the caller's friends voted. Only
+// friends who opted into showing their votes are included; Friends may be
+// truncated but the counts are complete.
+type FriendPicksResponse struct {
+	CountA  int          `json:"count_a"`
+	CountB  int          `json:"count_b"`
+	Friends []FriendPick `json:"friends"`
+}
+
+// --- Offline vote sync ---
+
+// SyncVoteItem is a vote cast while offline. VotedAt is the client clock.
+type SyncVoteItem struct {
+	ChallengeID string    `json:"challenge_id"`
+	Choice      string    `json:"choice"`
+	VotedAt     time.Time `json:"voted_at"`
+}
+
+type SyncVotesRequest struct {
+	Votes []SyncVoteItem `json:"votes"`
+}
+
+// SyncVoteResult reports what happened to one submitted vote.
+// Status is "accepted", "duplicate" or "rejected" (with a reason).
+type SyncVoteResult struct {
+	ChallengeID string `json:"challenge_id"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+type SyncVotesResponse struct {
+	Results    []SyncVoteResult `json:"results"`
+	Accepted   int              `json:"accepted"`
+	Duplicates int              `json:"duplicates"`
+	Rejected   int              `json:"rejected"`
+}
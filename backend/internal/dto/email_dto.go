@@ -0,0 +1,24 @@
+package dto
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email"`
+	Password string `json:"password"` // Required unless the account has no password (Apple Sign-In)
+}
+
+type EmailVerifiedResponse struct {
+	Email    string `json:"email"`
+	Verified bool   `json:"verified"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
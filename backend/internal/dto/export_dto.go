@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DataExportResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Status      string     `json:"status"` // pending, processing, ready, failed, expired
+	SizeBytes   int64      `json:"size_bytes,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"` // Signed, short-lived; only when ready
+	URLExpires  *time.Time `json:"url_expires_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // When the archive is deleted
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
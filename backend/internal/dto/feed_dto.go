@@ -0,0 +1,122 @@
+package dto
+
+import "encoding/xml"
+
+// --- RSS 2.0 ---
+
+type RSSFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel RSSChannel `xml:"channel"`
+}
+
+type RSSChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	TTL           int       `xml:"ttl"`
+	AtomLink      RSSLink   `xml:"atom:link"`
+	Items         []RSSItem `xml:"item"`
+}
+
+type RSSLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type RSSItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Category    string  `xml:"category,omitempty"`
+	GUID        RSSGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type RSSGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// --- Atom 1.0 ---
+
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []AtomLink  `xml:"link"`
+	Author  AtomPerson  `xml:"author"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomEntry struct {
+	Title     string        `xml:"title"`
+	ID        string        `xml:"id"`
+	Link      AtomLink      `xml:"link"`
+	Published string        `xml:"published"`
+	Updated   string        `xml:"updated"`
+	Category  *AtomCategory `xml:"category,omitempty"`
+	Summary   AtomText      `xml:"summary"`
+}
+
+type AtomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// --- JSON Feed 1.1 ---
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Language    string         `json:"language"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string             `json:"id"`
+	URL           string             `json:"url"`
+	Title         string             `json:"title"`
+	ContentText   string             `json:"content_text"`
+	DatePublished string             `json:"date_published"`
+	DateModified  string             `json:"date_modified"`
+	Tags          []string           `json:"tags,omitempty"`
+	WouldYou      JSONFeedChallenge  `json:"_wouldyou"`
+	Yesterday     *JSONFeedChallenge `json:"_wouldyou_yesterday,omitempty"`
+}
+
+// JSONFeedChallenge is the JSON Feed extension object carrying the raw
+// challenge so bots don't have to parse the human-readable title.
+type JSONFeedChallenge struct {
+	ID         string `json:"id"`
+	Date       string `json:"date"`
+	OptionA    string `json:"option_a"`
+	OptionB    string `json:"option_b"`
+	Category   string `json:"category"`
+	PercentA   *int   `json:"percent_a,omitempty"`
+	PercentB   *int   `json:"percent_b,omitempty"`
+	TotalVotes *int   `json:"total_votes,omitempty"`
+	URL        string `json:"url"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FriendUserResponse is another user as seen from the friends screens.
+type FriendUserResponse struct {
+	ID          uuid.UUID `json:"id"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	Status      string    `json:"status"` // "none", "friends", "outgoing" or "incoming"
+}
+
+type FriendResponse struct {
+	User  FriendUserResponse `json:"user"`
+	Since time.Time          `json:"since"`
+}
+
+type FriendRequestResponse struct {
+	ID        uuid.UUID          `json:"id"`
+	User      FriendUserResponse `json:"user"`
+	Direction string             `json:"direction"` // "incoming" or "outgoing"
+	Status    string             `json:"status"`    // "pending" or "accepted"
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type FriendRequestsResponse struct {
+	Incoming []FriendRequestResponse `json:"incoming"`
+	Outgoing []FriendRequestResponse `json:"outgoing"`
+}
+
+// SendFriendRequestRequest targets a user by ID (from search) or by their
+// friend code.
+type SendFriendRequestRequest struct {
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	FriendCode string     `json:"friend_code,omitempty"`
+}
+
+type FriendCodeResponse struct {
+	Code string `json:"code"`
+	Link string `json:"link"` // Deep link that opens the add-friend screen
+}
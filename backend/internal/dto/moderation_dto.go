@@ -5,7 +5,7 @@ import "github.com/google/uuid"
 // --- Report DTOs ---
 
 type CreateReportRequest struct {
-	ContentType string `json:"content_type"` // "user", "post", "comment"
+	ContentType string `json:"content_type"` // "user", "post", "comment", "challenge"
 	ContentID   string `json:"content_id"`
 	Reason      string `json:"reason"`
 }
@@ -22,7 +22,7 @@ type ReportResponse struct {
 }
 
 type ActionReportRequest struct {
-	Status    string `json:"status"`     // "reviewed", "actioned", "dismissed"
+	Status    string `json:"status"` // "reviewed", "actioned", "dismissed"
 	AdminNote string `json:"admin_note"`
 }
 
@@ -44,5 +44,8 @@ type AppleSignInRequest struct {
 	IdentityToken string `json:"identity_token"` // JWT from Apple
 	AuthCode      string `json:"authorization_code"`
 	FullName      string `json:"full_name,omitempty"`
-	Email         string `json:"email,omitempty"` // Only sent on first sign-in
+	Email         string `json:"email,omitempty"`       // Only sent on first sign-in
+	ShareCode     string `json:"share_code,omitempty"`  // Share link that led to the install
+	GuestID       string `json:"guest_id,omitempty"`    // Raw guest ID (legacy, see ALLOW_RAW_GUEST_IDS)
+	GuestToken    string `json:"guest_token,omitempty"` // Signed guest identity whose votes move to the account
 }
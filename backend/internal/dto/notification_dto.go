@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Payload   map[string]string `json:"payload"`
+	Read      bool              `json:"read"`
+	ReadAt    *time.Time        `json:"read_at"`
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type NotificationsResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	NextCursor    string                 `json:"next_cursor,omitempty"` // Pass as ?cursor= for the next page; empty on the last page
+	UnreadCount   int64                  `json:"unread_count"`
+}
@@ -0,0 +1,87 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// --- Admin pack management ---
+
+type CreatePackRequest struct {
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Category     string      `json:"category"`
+	IsPremium    bool        `json:"is_premium"`
+	ChallengeIDs []uuid.UUID `json:"challenge_ids"` // Ordered
+}
+
+// UpdatePackRequest is a partial update; omitted fields are left unchanged.
+// Sending challenge_ids replaces the whole ordered list.
+type UpdatePackRequest struct {
+	Name         *string     `json:"name"`
+	Description  *string     `json:"description"`
+	Category     *string     `json:"category"`
+	IsPremium    *bool       `json:"is_premium"`
+	ChallengeIDs []uuid.UUID `json:"challenge_ids"`
+}
+
+// --- Public listing ---
+
+type PackResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Category       string    `json:"category"`
+	IsPremium      bool      `json:"is_premium"`
+	Entitlement    string    `json:"entitlement,omitempty"` // Entitlement that unlocks a premium pack
+	ChallengeCount int       `json:"challenge_count"`
+	Version        int       `json:"version"`
+	Entitled       bool      `json:"entitled"` // Caller may download this pack
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// --- Offline download ---
+
+// PackChallenge is a challenge as cached by the app for offline play.
+type PackChallenge struct {
+	ID        uuid.UUID `json:"id"`
+	OptionA   string    `json:"option_a"`
+	OptionB   string    `json:"option_b"`
+	Category  string    `json:"category"`
+	SortOrder int       `json:"sort_order"`
+}
+
+// PackDownloadResponse is either a full pack (full=true, challenges set) or
+// a delta from from_version (added/changed/removed set). Clients apply the
+// delta and should end up with content matching content_hash.
+type PackDownloadResponse struct {
+	Pack        PackResponse    `json:"pack"`
+	Version     int             `json:"version"`
+	ContentHash string          `json:"content_hash"`
+	Full        bool            `json:"full"`
+	FromVersion int             `json:"from_version,omitempty"`
+	Challenges  []PackChallenge `json:"challenges,omitempty"`
+	Added       []PackChallenge `json:"added,omitempty"`
+	Changed     []PackChallenge `json:"changed,omitempty"`
+	Removed     []uuid.UUID     `json:"removed,omitempty"`
+}
+
+// --- Signed .wypack files ---
+
+type PackPublicKeyResponse struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"` // base64, raw 32-byte Ed25519 key
+	Format    int    `json:"format"`
+}
+
+type PackImportResponse struct {
+	PackID            uuid.UUID `json:"pack_id"`
+	Version           int       `json:"version"`
+	PackCreated       bool      `json:"pack_created"`
+	PackChanged       bool      `json:"pack_changed"`
+	ChallengesCreated int       `json:"challenges_created"`
+	ChallengesUpdated int       `json:"challenges_updated"`
+	ChallengesSkipped int       `json:"challenges_skipped"` // Removed locally (e.g. moderated)
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type XPCapStatus struct {
+	Source string `json:"source"` // "vote", "daily", "share" or "lobby_win"
+	Earned int    `json:"earned"` // Actions rewarded today
+	Cap    int    `json:"cap"`
+	XPEach int    `json:"xp_each"`
+}
+
+type SeasonResponse struct {
+	ID        string    `json:"id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Finalized bool      `json:"finalized"`
+}
+
+type SeasonStanding struct {
+	Season SeasonResponse `json:"season"`
+	XP     int            `json:"xp"`
+	Rank   int            `json:"rank"`            // 0 until the user earns XP this season
+	Prize  string         `json:"prize,omitempty"` // Entitlement won, for finalized seasons
+}
+
+type ProgressResponse struct {
+	TotalXP     int            `json:"total_xp"`
+	Level       int            `json:"level"`
+	LevelXP     int            `json:"level_xp"`      // XP earned within the current level
+	LevelSpan   int            `json:"level_span"`    // XP the current level takes in total
+	NextLevelAt int            `json:"next_level_at"` // Total XP needed for the next level
+	Season      SeasonStanding `json:"season"`
+	DailyCaps   []XPCapStatus  `json:"daily_caps"`
+}
+
+type LeaderboardEntry struct {
+	Rank        int       `json:"rank"`
+	UserID      uuid.UUID `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	XP          int       `json:"xp"`
+	Level       int       `json:"level"`
+}
+
+type LeaderboardResponse struct {
+	Season  SeasonResponse     `json:"season"`
+	Entries []LeaderboardEntry `json:"entries"`
+	Me      *LeaderboardEntry  `json:"me,omitempty"` // The caller's own standing, when signed in and ranked
+}
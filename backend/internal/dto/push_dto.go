@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type RegisterDeviceRequest struct {
+	Token          string `json:"token"`
+	Provider       string `json:"provider"`                  // "expo" or "apns"
+	Platform       string `json:"platform"`                  // "ios" or "android"
+	Timezone       string `json:"timezone"`                  // IANA name; defaults to UTC
+	DailyReminders *bool  `json:"daily_reminders,omitempty"` // Defaults to true
+}
+
+type UnregisterDeviceRequest struct {
+	Token string `json:"token"`
+}
+
+type DeviceResponse struct {
+	Token          string    `json:"token"`
+	Provider       string    `json:"provider"`
+	Platform       string    `json:"platform"`
+	Timezone       string    `json:"timezone"`
+	DailyReminders bool      `json:"daily_reminders"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import "github.com/google/uuid"
+
+type CreateShareRequest struct {
+	ChallengeID uuid.UUID `json:"challenge_id"`
+	Platform    string    `json:"platform"` // instagram, tiktok, imessage, whatsapp, x, other
+}
+
+type ShareResponse struct {
+	Code        string    `json:"code"`
+	URL         string    `json:"url"`
+	Platform    string    `json:"platform"`
+	ChallengeID uuid.UUID `json:"challenge_id"`
+}
+
+// SharedChallengeResponse is the public view of a share code.
+type SharedChallengeResponse struct {
+	Code         string    `json:"code"`
+	URL          string    `json:"url"`
+	ChallengeID  uuid.UUID `json:"challenge_id"`
+	OptionA      string    `json:"option_a"`
+	OptionB      string    `json:"option_b"`
+	Category     string    `json:"category"`
+	SharerChoice string    `json:"sharer_choice"` // "A", "B" or "" if the sharer hasn't voted
+	PercentA     int       `json:"percent_a"`
+	PercentB     int       `json:"percent_b"`
+	TotalVotes   int       `json:"total_votes"`
+}
+
+// --- Admin analytics ---
+
+// ShareAnalyticsRow aggregates shares for one challenge, platform or sharer.
+// Installs are accounts created from the share links; activated voters are
+// those installs that have voted at least once.
+type ShareAnalyticsRow struct {
+	Key             string  `json:"key"`   // challenge ID, platform or sharer user ID
+	Label           string  `json:"label"` // Challenge text or sharer email
+	Shares          int64   `json:"shares"`
+	Opens           int64   `json:"opens"`
+	Installs        int64   `json:"installs"`
+	ActivatedVoters int64   `json:"activated_voters"`
+	InstallRate     float64 `json:"install_rate"` // installs / opens
+}
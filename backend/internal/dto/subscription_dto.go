@@ -0,0 +1,10 @@
+package dto
+
+// EntitlementRequiredResponse is returned with 402 Payment Required when the
+// caller lacks the RevenueCat entitlement for a piece of content.
+type EntitlementRequiredResponse struct {
+	Error       bool   `json:"error"`
+	Code        string `json:"code"` // Always "entitlement_required"
+	Message     string `json:"message"`
+	Entitlement string `json:"entitlement"`
+}
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserProfileResponse struct {
+	ID                 uuid.UUID `json:"id"`
+	Email              string    `json:"email"`
+	EmailVerified      bool      `json:"email_verified"`
+	DisplayName        string    `json:"display_name"`
+	AvatarURL          string    `json:"avatar_url"`
+	ShowVotesToFriends bool      `json:"show_votes_to_friends"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// UpdateProfileRequest is a partial update; an empty display_name clears it.
+type UpdateProfileRequest struct {
+	DisplayName        *string `json:"display_name"`
+	ShowVotesToFriends *bool   `json:"show_votes_to_friends"` // Let friends see which option you picked
+}
+
+// --- Personal stats ---
+
+type CategoryStat struct {
+	Category string `json:"category"`
+	Votes    int    `json:"votes"`
+	Percent  int    `json:"percent"`
+}
+
+type ContrarianPickResponse struct {
+	ChallengeID  uuid.UUID `json:"challenge_id"`
+	OptionA      string    `json:"option_a"`
+	OptionB      string    `json:"option_b"`
+	Choice       string    `json:"choice"`
+	SharePercent int       `json:"share_percent"` // Share of voters who agreed
+}
+
+type PersonalityBadge struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UserStatsResponse struct {
+	TotalVotes       int                      `json:"total_votes"`
+	CurrentStreak    int                      `json:"current_streak"`
+	LongestStreak    int                      `json:"longest_streak"`
+	Categories       []CategoryStat           `json:"categories"`
+	FavoriteCategory string                   `json:"favorite_category"`
+	MainstreamScore  int                      `json:"mainstream_score"` // 0 (rebel) - 100 (mainstream), % of decided votes siding with the majority
+	MajorityVotes    int                      `json:"majority_votes"`
+	DecidedVotes     int                      `json:"decided_votes"`
+	ContrarianPicks  []ContrarianPickResponse `json:"contrarian_picks"`
+	Badge            PersonalityBadge         `json:"badge"`
+	UpdatedAt        time.Time                `json:"updated_at"`
+}
+
+type PersonalityResponse struct {
+	Summary       string           `json:"summary"`
+	Badge         PersonalityBadge `json:"badge"`
+	Source        string           `json:"source"` // "ai" or "fallback"
+	VotesAnalyzed int              `json:"votes_analyzed"`
+	RefreshIn     int              `json:"refresh_in"` // Votes until the summary is regenerated
+	GeneratedAt   time.Time        `json:"generated_at"`
+}
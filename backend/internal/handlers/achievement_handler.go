@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AchievementHandler struct {
+	achievementService *services.AchievementService
+}
+
+func NewAchievementHandler(achievementService *services.AchievementService) *AchievementHandler {
+	return &AchievementHandler{achievementService: achievementService}
+}
+
+// ListMine handles GET /api/users/me/achievements
+func (h *AchievementHandler) ListMine(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	achievements, err := h.achievementService.ListForUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to get achievements",
+		})
+	}
+
+	return c.JSON(achievements)
+}
+
+// --- Admin endpoints ---
+
+// AdminList handles GET /api/admin/achievements
+func (h *AchievementHandler) AdminList(c *fiber.Ctx) error {
+	achievements, err := h.achievementService.AdminList()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch achievements",
+		})
+	}
+
+	return c.JSON(fiber.Map{"achievements": achievements})
+}
+
+// AdminUpsert handles PUT /api/admin/achievements/:id, creating or
+// replacing the definition.
+func (h *AchievementHandler) AdminUpsert(c *fiber.Ctx) error {
+	var req dto.UpsertAchievementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	achievement, err := h.achievementService.Upsert(c.Params("id"), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAchievement) || errors.Is(err, services.ErrInvalidAchievementRule) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrAchievementNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to save achievement",
+		})
+	}
+
+	return c.JSON(achievement)
+}
@@ -24,6 +24,9 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 			Message: "Invalid request body",
 		})
 	}
+	if req.Locale == "" {
+		req.Locale = c.Get(fiber.HeaderAcceptLanguage)
+	}
 
 	resp, err := h.authService.Register(&req)
 	if err != nil {
@@ -113,6 +116,123 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"message": "Logged out successfully"})
 }
 
+// Guest handles POST /api/auth/guest, issuing or renewing a signed guest
+// identity for voting without an account.
+func (h *AuthHandler) Guest(c *fiber.Ctx) error {
+	var req dto.GuestTokenRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "Invalid request body",
+			})
+		}
+	}
+
+	resp, err := h.authService.IssueGuestToken(&req, c.IP())
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidGuestToken) {
+			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrTooManyGuests) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to issue guest token",
+		})
+	}
+
+	return c.JSON(resp)
+}
+
+// ForgotPassword handles POST /api/auth/password/forgot. It always answers
+// 202 so it can't be used to find out which emails are registered.
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req dto.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	h.authService.RequestPasswordReset(&req)
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "If an account exists for that email, a reset link is on its way.",
+	})
+}
+
+// ResetPassword handles POST /api/auth/password/reset
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req dto.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	if err := h.authService.ResetPassword(&req); err != nil {
+		if errors.Is(err, services.ErrWeakPassword) || errors.Is(err, services.ErrEmailTokenInvalid) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to reset password",
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Password updated. Please sign in again."})
+}
+
+// ResetLink handles GET /api/auth/password/reset?token=..., the link opened
+// from the reset email. It offers to continue in the app and falls back to
+// a web form; the token is only consumed when that form is posted.
+func (h *AuthHandler) ResetLink(c *fiber.Ctx) error {
+	return renderEmailPage(c, fiber.StatusOK, resetPasswordPage(c.Query("token"), ""))
+}
+
+// ConfirmResetLink handles POST /api/auth/password/reset/link, the form on
+// the ResetLink page. It answers with a small HTML page.
+func (h *AuthHandler) ConfirmResetLink(c *fiber.Ctx) error {
+	req := dto.ResetPasswordRequest{Token: c.FormValue("token"), NewPassword: c.FormValue("new_password")}
+
+	if err := h.authService.ResetPassword(&req); err != nil {
+		switch {
+		case errors.Is(err, services.ErrWeakPassword):
+			// Checked before the token is redeemed, so the form can be retried
+			return renderEmailPage(c, fiber.StatusBadRequest, resetPasswordPage(req.Token, "Use at least 8 characters."))
+		case errors.Is(err, services.ErrEmailTokenInvalid):
+			return renderEmailPage(c, fiber.StatusBadRequest, emailPage{
+				Title: "Link not valid", Message: "This link is invalid or has expired. Request a new one from the app.",
+			})
+		}
+		return renderEmailPage(c, fiber.StatusInternalServerError, emailPage{
+			Title: "Something went wrong", Message: "Your password wasn't changed. Please try again.",
+		})
+	}
+
+	return renderEmailPage(c, fiber.StatusOK, emailPage{
+		Title: "Password updated", Message: "Sign in to WouldYou with your new password.",
+	})
+}
+
+func resetPasswordPage(token, errMessage string) emailPage {
+	return emailPage{
+		Title:    "Reset your password",
+		Message:  "Continue in the WouldYou app, or choose a new password here.",
+		Error:    errMessage,
+		AppLink:  services.PasswordResetAppLink(token),
+		Action:   "/api/auth/password/reset/link",
+		Token:    token,
+		Password: true,
+		Button:   "Set new password",
+	}
+}
+
 // DeleteAccount implements Apple Guideline 5.1.1(v) — account deletion with full data scrub.
 func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
 	userID, err := extractUserID(c)
@@ -129,7 +249,8 @@ func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.authService.DeleteAccount(userID, req.Password); err != nil {
+	purgeAfter, err := h.authService.DeleteAccount(userID, req.Password)
+	if err != nil {
 		if errors.Is(err, services.ErrInvalidCredentials) {
 			return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
 				Error: true, Message: "Incorrect password",
@@ -145,7 +266,10 @@ func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{"message": "Account deleted successfully"})
+	return c.JSON(fiber.Map{
+		"message":     "Account scheduled for deletion. Sign in again before the purge date to restore it.",
+		"purge_after": purgeAfter,
+	})
 }
 
 // AppleSignIn handles Sign in with Apple (Guideline 4.8).
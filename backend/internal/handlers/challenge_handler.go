@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
 
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/middleware"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -10,14 +15,18 @@ import (
 )
 
 type ChallengeHandler struct {
-	service           *services.ChallengeService
-	questionGenerator *services.QuestionGeneratorService
+	service             *services.ChallengeService
+	questionGenerator   *services.QuestionGeneratorService
+	subscriptionService *services.SubscriptionService
+	friendService       *services.FriendService
 }
 
-func NewChallengeHandler(service *services.ChallengeService, qg *services.QuestionGeneratorService) *ChallengeHandler {
+func NewChallengeHandler(service *services.ChallengeService, qg *services.QuestionGeneratorService, subs *services.SubscriptionService, friends *services.FriendService) *ChallengeHandler {
 	return &ChallengeHandler{
-		service:           service,
-		questionGenerator: qg,
+		service:             service,
+		questionGenerator:   qg,
+		subscriptionService: subs,
+		friendService:       friends,
 	}
 }
 
@@ -52,14 +61,24 @@ func (h *ChallengeHandler) GetDailyChallenge(c *fiber.Ctx) error {
 		percentB = (challenge.VotesB * 100) / total
 	}
 
-	return c.JSON(fiber.Map{
+	resp := fiber.Map{
 		"challenge":   challenge,
 		"user_choice": userChoice,
 		"user_voted":  userChoice != "",
 		"percent_a":   percentA,
 		"percent_b":   percentB,
 		"total_votes": total,
-	})
+	}
+
+	// Friends' picks are revealed once the user has voted
+	if userID != uuid.Nil && userChoice != "" {
+		picks, err := h.friendService.FriendPicks(userID, []uuid.UUID{challenge.ID})
+		if err == nil {
+			resp["friend_picks"] = friendPicksOrEmpty(picks, challenge.ID)
+		}
+	}
+
+	return c.JSON(resp)
 }
 
 // Vote supports both authenticated users and guests via OptionalAuth
@@ -91,12 +110,58 @@ func (h *ChallengeHandler) Vote(c *fiber.Ctx) error {
 
 	vote, err := h.service.Vote(userID, guestID, challengeID, req.Choice)
 	if err != nil {
+		var entErr *services.EntitlementError
+		if errors.As(err, &entErr) {
+			return middleware.EntitlementRequired(c, entErr.Entitlement)
+		}
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": true, "message": err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(vote)
+	// Voting reveals friends' picks, so send them along with the vote
+	resp := struct {
+		*models.Vote
+		FriendPicks *dto.FriendPicksResponse `json:"friend_picks,omitempty"`
+	}{Vote: vote}
+	if userID != uuid.Nil {
+		if picks, err := h.friendService.FriendPicks(userID, []uuid.UUID{challengeID}); err == nil {
+			resp.FriendPicks = friendPicksOrEmpty(picks, challengeID)
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// SyncVotes handles POST /api/challenges/sync
+// Uploads votes cast offline; each item is accepted, duplicate or rejected.
+func (h *ChallengeHandler) SyncVotes(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": true, "message": "Unauthorized",
+		})
+	}
+
+	var req dto.SyncVotesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": true, "message": "Invalid request body",
+		})
+	}
+
+	if len(req.Votes) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": true, "message": "votes is required",
+		})
+	}
+	if len(req.Votes) > services.MaxSyncBatch {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": true, "message": fmt.Sprintf("Cannot sync more than %d votes at once", services.MaxSyncBatch),
+		})
+	}
+
+	return c.JSON(h.service.SyncVotes(userID, req.Votes))
 }
 
 // GetStats requires authenticated user
@@ -131,11 +196,12 @@ func (h *ChallengeHandler) GetHistory(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"data": history})
 }
 
-// GetRandom returns a random challenge the user hasn't voted on
+// GetRandom returns a random challenge the user hasn't voted on,
+// skipping premium categories the caller isn't entitled to.
 func (h *ChallengeHandler) GetRandom(c *fiber.Ctx) error {
 	userID, _ := extractIdentity(c)
 
-	challenge, err := h.service.GetRandomChallenge(userID)
+	challenge, err := h.service.GetRandomChallenge(userID, h.subscriptionService.LockedCategories(userID))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": true, "message": "No challenges available",
@@ -176,6 +242,23 @@ func (h *ChallengeHandler) GetByCategory(c *fiber.Ctx) error {
 		})
 	}
 
+	// Friends' picks for every challenge the user has voted on, batched
+	if userID != uuid.Nil {
+		var voted []uuid.UUID
+		for _, item := range challenges {
+			if item["user_choice"] != "" {
+				voted = append(voted, item["challenge"].(models.Challenge).ID)
+			}
+		}
+		if picks, err := h.friendService.FriendPicks(userID, voted); err == nil {
+			for _, item := range challenges {
+				if item["user_choice"] != "" {
+					item["friend_picks"] = friendPicksOrEmpty(picks, item["challenge"].(models.Challenge).ID)
+				}
+			}
+		}
+	}
+
 	return c.JSON(fiber.Map{"data": challenges, "total": len(challenges)})
 }
 
@@ -275,6 +358,15 @@ func (h *ChallengeHandler) GenerateAllCategories(c *fiber.Ctx) error {
 	})
 }
 
+// friendPicksOrEmpty returns the picks for one challenge, with zero counts
+// when no visible friend voted on it.
+func friendPicksOrEmpty(picks map[uuid.UUID]*dto.FriendPicksResponse, challengeID uuid.UUID) *dto.FriendPicksResponse {
+	if p, ok := picks[challengeID]; ok {
+		return p
+	}
+	return &dto.FriendPicksResponse{Friends: []dto.FriendPick{}}
+}
+
 // extractIdentity gets userID and guestID from OptionalAuth middleware locals
 func extractIdentity(c *fiber.Ctx) (uuid.UUID, string) {
 	userID := uuid.Nil
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type DeviceHandler struct {
+	pushService *services.PushService
+}
+
+func NewDeviceHandler(pushService *services.PushService) *DeviceHandler {
+	return &DeviceHandler{pushService: pushService}
+}
+
+// Register handles POST /api/devices for signed-in users and guests.
+func (h *DeviceHandler) Register(c *fiber.Ctx) error {
+	userID, guestID := extractIdentity(c)
+	if userID == uuid.Nil && guestID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Sign in or send a guest token to register a device",
+		})
+	}
+
+	var req dto.RegisterDeviceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	device, err := h.pushService.RegisterDevice(userID, guestID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDeviceToken) ||
+			errors.Is(err, services.ErrUnsupportedPushProvider) ||
+			errors.Is(err, services.ErrInvalidTimezone) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to register device",
+		})
+	}
+
+	return c.JSON(device)
+}
+
+// Unregister handles DELETE /api/devices. The token goes in the body
+// because Expo tokens contain characters that are awkward in a path.
+func (h *DeviceHandler) Unregister(c *fiber.Ctx) error {
+	userID, guestID := extractIdentity(c)
+	if userID == uuid.Nil && guestID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	var req dto.UnregisterDeviceRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "token is required",
+		})
+	}
+
+	if err := h.pushService.UnregisterDevice(userID, guestID, req.Token); err != nil {
+		if errors.Is(err, services.ErrDeviceNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to unregister device",
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Device unregistered"})
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type EmailHandler struct {
+	emailService *services.EmailService
+}
+
+func NewEmailHandler(emailService *services.EmailService) *EmailHandler {
+	return &EmailHandler{emailService: emailService}
+}
+
+// Verify handles POST /api/auth/email/verify with {"token": "..."}, used by
+// the app when it intercepts the emailed link.
+func (h *EmailHandler) Verify(c *fiber.Ctx) error {
+	var req dto.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	resp, err := h.emailService.Verify(req.Token)
+	if err != nil {
+		return emailError(c, err)
+	}
+
+	return c.JSON(resp)
+}
+
+// VerifyLink handles GET /api/auth/email/verify?token=..., the link opened
+// from the email in a browser. It only shows a confirm button: link
+// scanners and previews fetch GET URLs, so the single-use token is consumed
+// by the POST the button sends.
+func (h *EmailHandler) VerifyLink(c *fiber.Ctx) error {
+	return renderEmailPage(c, fiber.StatusOK, emailPage{
+		Title:   "Confirm your email",
+		Message: "Tap the button below to confirm this email address for your WouldYou account.",
+		Action:  "/api/auth/email/verify/link",
+		Token:   c.Query("token"),
+		Button:  "Confirm email",
+	})
+}
+
+// ConfirmLink handles POST /api/auth/email/verify/link, the form on the
+// VerifyLink page. It answers with a small HTML page.
+func (h *EmailHandler) ConfirmLink(c *fiber.Ctx) error {
+	page := emailPage{Title: "Email confirmed", Message: "You're all set. You can return to the app."}
+	status := fiber.StatusOK
+
+	if _, err := h.emailService.Verify(c.FormValue("token")); err != nil {
+		page = emailPage{Title: "Link not valid", Message: "This link is invalid or has expired. Request a new one from the app."}
+		status = fiber.StatusBadRequest
+		if errors.Is(err, services.ErrEmailTaken) {
+			page = emailPage{Title: "Email unavailable", Message: "That email address is already used by another account."}
+			status = fiber.StatusConflict
+		}
+	}
+
+	return renderEmailPage(c, status, page)
+}
+
+// Resend handles POST /api/auth/email/resend
+func (h *EmailHandler) Resend(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	if err := h.emailService.ResendVerification(userID); err != nil {
+		return emailError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Verification email sent"})
+}
+
+// ChangeEmail handles POST /api/auth/email/change. The new address only
+// replaces the current one once confirmed from the emailed link.
+func (h *EmailHandler) ChangeEmail(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	var req dto.ChangeEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	if err := h.emailService.RequestEmailChange(userID, &req); err != nil {
+		return emailError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Confirmation sent to the new address"})
+}
+
+func emailError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Incorrect password",
+		})
+	case errors.Is(err, services.ErrEmailTaken), errors.Is(err, services.ErrEmailAlreadyVerified):
+		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrEmailResendTooSoon):
+		return c.Status(fiber.StatusTooManyRequests).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidEmail),
+		errors.Is(err, services.ErrEmailUnchanged),
+		errors.Is(err, services.ErrEmailTokenInvalid):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error: true, Message: "Failed to send email",
+	})
+}
+
+// emailPage is a minimal HTML page for links opened from emails. With an
+// Action it shows a form posting Token there, plus a new password field
+// when Password is set. AppLink offers to continue in the app instead.
+type emailPage struct {
+	Title    string
+	Message  string
+	Error    string
+	AppLink  template.URL
+	Action   string
+	Token    string
+	Password bool
+	Button   string
+}
+
+func renderEmailPage(c *fiber.Ctx, status int, page emailPage) error {
+	var body bytes.Buffer
+	if err := emailPageTemplate.Execute(&body, page); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to render page",
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set("Referrer-Policy", "no-referrer") // Keep the token out of Referer headers
+	c.Type("html", "utf-8")
+	return c.Status(status).Send(body.Bytes())
+}
+
+var emailPageTemplate = template.Must(template.New("email-page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} - WouldYou</title>
+<style>body{margin:0;min-height:100vh;display:flex;align-items:center;justify-content:center;background:#0F0F1A;color:#FFFFFF;font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,sans-serif;text-align:center}main{padding:32px;max-width:420px}p{color:#A0A0B8}.button,button{display:block;box-sizing:border-box;width:100%;margin-top:16px;padding:16px 28px;border:0;border-radius:16px;background:#FF6B9D;color:#FFFFFF;font-size:16px;font-weight:700;text-decoration:none}input{display:block;box-sizing:border-box;width:100%;margin-top:24px;padding:14px;border:0;border-radius:12px;background:#2A2A4A;color:#FFFFFF;font-size:16px}.error{color:#FF6B6B}</style>
+</head>
+<body><main><h1>{{.Title}}</h1><p>{{.Message}}</p>
+{{- if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{- if .AppLink}}
+<a class="button" href="{{.AppLink}}">Open in the app</a>
+{{- end}}
+{{- if .Action}}
+<form method="post" action="{{.Action}}"><input type="hidden" name="token" value="{{.Token}}">
+{{- if .Password}}<input type="password" name="new_password" placeholder="New password" minlength="8" required autocomplete="new-password">{{end}}
+<button type="submit">{{.Button}}</button></form>
+{{- end}}
+</main></body>
+</html>`))
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ExportHandler struct {
+	exportService *services.ExportService
+}
+
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// RequestExport handles POST /api/users/me/exports
+func (h *ExportHandler) RequestExport(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	export, err := h.exportService.RequestExport(userID)
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(export)
+}
+
+// ListExports handles GET /api/users/me/exports
+func (h *ExportHandler) ListExports(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	exports, err := h.exportService.ListExports(userID)
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"exports": exports})
+}
+
+// GetExport handles GET /api/users/me/exports/:id. Ready exports include a
+// fresh signed download URL.
+func (h *ExportHandler) GetExport(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	exportID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid export ID",
+		})
+	}
+
+	export, err := h.exportService.GetExport(userID, exportID)
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	return c.JSON(export)
+}
+
+// Download handles GET /api/exports/:id/download?expires=...&sig=...
+// The signature authorizes the request, so no JWT is needed.
+func (h *ExportHandler) Download(c *fiber.Ctx) error {
+	exportID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return exportError(c, services.ErrExportLinkInvalid)
+	}
+
+	rc, filename, err := h.exportService.OpenDownload(exportID, c.Query("expires"), c.Query("sig"))
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.SendStream(rc)
+}
+
+func exportError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrExportNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrExportLinkInvalid):
+		return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrExportNotReady):
+		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrExportRateLimited):
+		return c.Status(fiber.StatusTooManyRequests).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error: true, Message: "Failed to process data export",
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+const dailyFeedSize = 30
+
+type FeedHandler struct {
+	feedService *services.FeedService
+}
+
+func NewFeedHandler(feedService *services.FeedService) *FeedHandler {
+	return &FeedHandler{feedService: feedService}
+}
+
+// DailyRSS handles GET /feeds/daily.rss
+func (h *FeedHandler) DailyRSS(c *fiber.Ctx) error {
+	return h.serve(c, "application/rss+xml; charset=utf-8", h.feedService.RenderRSS)
+}
+
+// DailyAtom handles GET /feeds/daily.atom
+func (h *FeedHandler) DailyAtom(c *fiber.Ctx) error {
+	return h.serve(c, "application/atom+xml; charset=utf-8", h.feedService.RenderAtom)
+}
+
+// DailyJSON handles GET /feeds/daily.json
+func (h *FeedHandler) DailyJSON(c *fiber.Ctx) error {
+	return h.serve(c, "application/feed+json; charset=utf-8", h.feedService.RenderJSON)
+}
+
+func (h *FeedHandler) serve(c *fiber.Ctx, contentType string, render func(*services.DailyFeed) ([]byte, error)) error {
+	feed, err := h.feedService.GetDailyFeed(dailyFeedSize)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to build feed",
+		})
+	}
+
+	body, err := render(feed)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to render feed",
+		})
+	}
+
+	return sendCacheable(c, body, contentType, feed.LastModified, "public, max-age=300")
+}
+
+// sendCacheable writes body with ETag/Last-Modified validators and answers
+// conditional requests with 304 Not Modified.
+func sendCacheable(c *fiber.Ctx, body []byte, contentType string, lastModified time.Time, cacheControl string) error {
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, cacheControl)
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110 13.2.2)
+	if inm := c.Get(fiber.HeaderIfNoneMatch); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return c.SendStatus(fiber.StatusNotModified)
+			}
+		}
+	} else if ims := c.Get(fiber.HeaderIfModifiedSince); ims != "" && !lastModified.IsZero() {
+		if since, err := http.ParseTime(ims); err == nil && !lastModified.Truncate(time.Second).After(since) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(body)
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type FriendHandler struct {
+	friendService *services.FriendService
+}
+
+func NewFriendHandler(friendService *services.FriendService) *FriendHandler {
+	return &FriendHandler{friendService: friendService}
+}
+
+// ListFriends handles GET /api/friends
+func (h *FriendHandler) ListFriends(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	friends, err := h.friendService.ListFriends(userID)
+	if err != nil {
+		return friendError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"friends": friends})
+}
+
+// RemoveFriend handles DELETE /api/friends/:id
+func (h *FriendHandler) RemoveFriend(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	friendID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid user ID",
+		})
+	}
+
+	if err := h.friendService.RemoveFriend(userID, friendID); err != nil {
+		return friendError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Friend removed"})
+}
+
+// ListRequests handles GET /api/friends/requests
+func (h *FriendHandler) ListRequests(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	requests, err := h.friendService.ListRequests(userID)
+	if err != nil {
+		return friendError(c, err)
+	}
+
+	return c.JSON(requests)
+}
+
+// SendRequest handles POST /api/friends/requests
+func (h *FriendHandler) SendRequest(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	var req dto.SendFriendRequestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+	if req.UserID == nil && req.FriendCode == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "user_id or friend_code is required",
+		})
+	}
+
+	request, err := h.friendService.SendRequest(userID, &req)
+	if err != nil {
+		return friendError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(request)
+}
+
+// AcceptRequest handles POST /api/friends/requests/:id/accept
+func (h *FriendHandler) AcceptRequest(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request ID",
+		})
+	}
+
+	friend, err := h.friendService.AcceptRequest(userID, requestID)
+	if err != nil {
+		return friendError(c, err)
+	}
+
+	return c.JSON(friend)
+}
+
+// DeclineRequest handles POST /api/friends/requests/:id/decline
+func (h *FriendHandler) DeclineRequest(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request ID",
+		})
+	}
+
+	if err := h.friendService.DeclineRequest(userID, requestID); err != nil {
+		return friendError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Friend request declined"})
+}
+
+// CancelRequest handles DELETE /api/friends/requests/:id
+func (h *FriendHandler) CancelRequest(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request ID",
+		})
+	}
+
+	if err := h.friendService.CancelRequest(userID, requestID); err != nil {
+		return friendError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Friend request cancelled"})
+}
+
+// Search handles GET /api/friends/search?q=
+func (h *FriendHandler) Search(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	users, err := h.friendService.Search(userID, c.Query("q"))
+	if err != nil {
+		return friendError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"users": users})
+}
+
+// MyCode handles GET /api/friends/code
+func (h *FriendHandler) MyCode(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	code, err := h.friendService.FriendCode(userID)
+	if err != nil {
+		return friendError(c, err)
+	}
+
+	return c.JSON(code)
+}
+
+// LookupCode handles GET /api/friends/code/:code
+func (h *FriendHandler) LookupCode(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	user, err := h.friendService.LookupCode(userID, c.Params("code"))
+	if err != nil {
+		return friendError(c, err)
+	}
+
+	return c.JSON(user)
+}
+
+func friendError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, services.ErrFriendNotFound),
+		errors.Is(err, services.ErrFriendRequestNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrAlreadyFriends),
+		errors.Is(err, services.ErrFriendRequestExists),
+		errors.Is(err, services.ErrFriendLimit):
+		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrSelfFriend),
+		errors.Is(err, services.ErrInvalidFriendCode),
+		errors.Is(err, services.ErrSearchQueryTooShort):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error: true, Message: "Internal server error",
+	})
+}
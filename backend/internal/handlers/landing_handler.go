@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type LandingHandler struct {
+	landingService *services.LandingService
+}
+
+func NewLandingHandler(landingService *services.LandingService) *LandingHandler {
+	return &LandingHandler{landingService: landingService}
+}
+
+// SharePage handles GET /s/:code
+// Serves Open Graph/Twitter meta for link unfurlers and sends people to the
+// app, falling back to the store. ?embed=1 renders the compact oEmbed view.
+func (h *LandingHandler) SharePage(c *fiber.Ctx) error {
+	page, err := h.landingService.SharePage(c.Params("code"), c.Query("via"))
+	if err != nil {
+		return landingError(c, err)
+	}
+	return h.render(c, page, "public, max-age=60")
+}
+
+// DailyPage handles GET /daily/:date (YYYY-MM-DD).
+func (h *LandingHandler) DailyPage(c *fiber.Ctx) error {
+	page, err := h.landingService.DailyPage(c.Params("date"))
+	if err != nil {
+		return landingError(c, err)
+	}
+	return h.render(c, page, "public, max-age=300")
+}
+
+// DailyCard handles GET /daily/:date/card.png
+func (h *LandingHandler) DailyCard(c *fiber.Ctx) error {
+	card, err := h.landingService.DailyCard(c.Params("date"))
+	if err != nil {
+		return landingError(c, err)
+	}
+	return sendCacheable(c, card.PNG, "image/png", card.RenderedAt, "public, max-age=60")
+}
+
+// OEmbed handles GET /oembed?url=...&maxwidth=&maxheight=&format=json
+func (h *LandingHandler) OEmbed(c *fiber.Ctx) error {
+	if format := c.Query("format", "json"); format != "json" {
+		return c.Status(fiber.StatusNotImplemented).JSON(dto.ErrorResponse{
+			Error: true, Message: "Only format=json is supported",
+		})
+	}
+
+	maxWidth, _ := strconv.Atoi(c.Query("maxwidth"))
+	maxHeight, _ := strconv.Atoi(c.Query("maxheight"))
+
+	resp, lastModified, err := h.landingService.OEmbed(c.Query("url"), maxWidth, maxHeight)
+	if err != nil {
+		return landingError(c, err)
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to build oEmbed response",
+		})
+	}
+
+	return sendCacheable(c, body, fiber.MIMEApplicationJSON, lastModified, "public, max-age=300")
+}
+
+func (h *LandingHandler) render(c *fiber.Ctx, page *services.LandingPage, cacheControl string) error {
+	page.Embed = c.Query("embed") == "1"
+
+	body, err := h.landingService.RenderHTML(page)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to render page",
+		})
+	}
+
+	return sendCacheable(c, body, "text/html; charset=utf-8", page.LastModified, cacheControl)
+}
+
+func landingError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrLandingNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error: true, Message: "Failed to load page",
+	})
+}
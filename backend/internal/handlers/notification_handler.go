@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// List handles GET /api/notifications?cursor=&limit=&unread=true
+func (h *NotificationHandler) List(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	notifications, err := h.notificationService.List(userID, c.Query("cursor"), c.QueryInt("limit", 0), c.QueryBool("unread", false))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to get notifications",
+		})
+	}
+
+	return c.JSON(notifications)
+}
+
+// MarkRead handles POST /api/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	notificationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid notification ID",
+		})
+	}
+
+	notification, err := h.notificationService.MarkRead(userID, notificationID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to update notification",
+		})
+	}
+
+	return c.JSON(notification)
+}
+
+// MarkAllRead handles POST /api/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	updated, err := h.notificationService.MarkAllRead(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to update notifications",
+		})
+	}
+
+	return c.JSON(fiber.Map{"updated": updated})
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/middleware"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PackHandler struct {
+	packService *services.PackService
+	packSigner  *services.PackSigner
+}
+
+func NewPackHandler(packService *services.PackService, packSigner *services.PackSigner) *PackHandler {
+	return &PackHandler{packService: packService, packSigner: packSigner}
+}
+
+// --- Public endpoints ---
+
+// ListPacks handles GET /api/packs (optional auth).
+// Each pack carries an "entitled" flag for the caller.
+func (h *PackHandler) ListPacks(c *fiber.Ctx) error {
+	userID, _ := extractIdentity(c)
+
+	packs, err := h.packService.ListPacks(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch packs",
+		})
+	}
+
+	return c.JSON(fiber.Map{"data": packs, "total": len(packs)})
+}
+
+// PublicKey handles GET /api/packs/public-key
+// Clients use it to verify the signature of cached .wypack files.
+func (h *PackHandler) PublicKey(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.JSON(h.packSigner.PublicKey())
+}
+
+// DownloadPack handles GET /api/packs/:id/download (optional auth).
+// Pass ?version=N with the cached version to receive only the delta, or
+// ?format=wypack to receive the signed pack file.
+func (h *PackHandler) DownloadPack(c *fiber.Ctx) error {
+	packID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid pack ID",
+		})
+	}
+
+	since, _ := strconv.Atoi(c.Query("version", "0"))
+	userID, _ := extractIdentity(c)
+
+	if c.Query("format") == "wypack" {
+		data, filename, err := h.packService.DownloadPackFile(packID, userID)
+		if err != nil {
+			return packDownloadError(c, err)
+		}
+		return sendWypack(c, data, filename)
+	}
+
+	resp, err := h.packService.DownloadPack(packID, userID, since)
+	if err != nil {
+		return packDownloadError(c, err)
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to download pack",
+		})
+	}
+
+	return sendCacheable(c, body, fiber.MIMEApplicationJSON, resp.Pack.UpdatedAt, "private, no-cache")
+}
+
+// --- Admin endpoints ---
+
+// AdminListPacks handles GET /api/admin/packs
+func (h *PackHandler) AdminListPacks(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	packs, total, err := h.packService.AdminListPacks(limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to fetch packs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"packs":  packs,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// AdminGetPack handles GET /api/admin/packs/:id
+func (h *PackHandler) AdminGetPack(c *fiber.Ctx) error {
+	packID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid pack ID",
+		})
+	}
+
+	pack, err := h.packService.GetPack(packID)
+	if err != nil {
+		return packError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"pack": pack, "items": pack.Items})
+}
+
+// CreatePack handles POST /api/admin/packs
+func (h *PackHandler) CreatePack(c *fiber.Ctx) error {
+	var req dto.CreatePackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	pack, err := h.packService.CreatePack(&req)
+	if err != nil {
+		return packError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"pack": pack, "items": pack.Items})
+}
+
+// UpdatePack handles PUT /api/admin/packs/:id
+func (h *PackHandler) UpdatePack(c *fiber.Ctx) error {
+	packID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid pack ID",
+		})
+	}
+
+	var req dto.UpdatePackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	pack, err := h.packService.UpdatePack(packID, &req)
+	if err != nil {
+		return packError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"pack": pack, "items": pack.Items})
+}
+
+// DeletePack handles DELETE /api/admin/packs/:id
+func (h *PackHandler) DeletePack(c *fiber.Ctx) error {
+	packID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid pack ID",
+		})
+	}
+
+	if err := h.packService.DeletePack(packID); err != nil {
+		return packError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Pack deleted successfully"})
+}
+
+// ExportPack handles GET /api/admin/packs/:id/export
+func (h *PackHandler) ExportPack(c *fiber.Ctx) error {
+	packID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid pack ID",
+		})
+	}
+
+	data, filename, err := h.packService.ExportPack(packID)
+	if err != nil {
+		return packError(c, err)
+	}
+
+	return sendWypack(c, data, filename)
+}
+
+// ImportPack handles POST /api/admin/packs/import
+// Accepts the .wypack either as the raw request body or as a multipart
+// "file" field.
+func (h *PackHandler) ImportPack(c *fiber.Ctx) error {
+	data := c.Body()
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "Failed to read uploaded file",
+			})
+		}
+		defer f.Close()
+		if data, err = io.ReadAll(f); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "Failed to read uploaded file",
+			})
+		}
+	}
+
+	resp, err := h.packService.ImportPack(data)
+	if err != nil {
+		if errors.Is(err, services.ErrWypackUntrustedKey) || errors.Is(err, services.ErrWypackBadSignature) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return packError(c, err)
+	}
+
+	status := fiber.StatusOK
+	if resp.PackCreated {
+		status = fiber.StatusCreated
+	}
+	return c.Status(status).JSON(resp)
+}
+
+func sendWypack(c *fiber.Ctx, data []byte, filename string) error {
+	c.Set(fiber.HeaderContentType, "application/vnd.wouldyou.pack")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Send(data)
+}
+
+func packDownloadError(c *fiber.Ctx, err error) error {
+	var entErr *services.EntitlementError
+	if errors.As(err, &entErr) {
+		return middleware.EntitlementRequired(c, entErr.Entitlement)
+	}
+	if errors.Is(err, services.ErrPackNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error: true, Message: "Failed to download pack",
+	})
+}
+
+func packError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrPackNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidPackChallenges),
+		errors.Is(err, services.ErrPackTooLarge),
+		errors.Is(err, services.ErrPackFieldsRequired),
+		errors.Is(err, services.ErrPackNameEmpty),
+		errors.Is(err, services.ErrPackCategoryEmpty),
+		errors.Is(err, services.ErrWypackMalformed),
+		errors.Is(err, services.ErrWypackUnsupported):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error: true, Message: "Failed to process pack",
+	})
+}
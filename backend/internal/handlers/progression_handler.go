@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProgressionHandler struct {
+	progressionService *services.ProgressionService
+}
+
+func NewProgressionHandler(progressionService *services.ProgressionService) *ProgressionHandler {
+	return &ProgressionHandler{progressionService: progressionService}
+}
+
+// MyProgress handles GET /api/users/me/progress
+func (h *ProgressionHandler) MyProgress(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	progress, err := h.progressionService.GetProgress(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to get progress",
+		})
+	}
+
+	return c.JSON(progress)
+}
+
+// MySeasons handles GET /api/users/me/seasons
+func (h *ProgressionHandler) MySeasons(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	seasons, err := h.progressionService.SeasonHistory(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to get season history",
+		})
+	}
+
+	return c.JSON(fiber.Map{"seasons": seasons})
+}
+
+// ListSeasons handles GET /api/seasons
+func (h *ProgressionHandler) ListSeasons(c *fiber.Ctx) error {
+	seasons, err := h.progressionService.ListSeasons()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to get seasons",
+		})
+	}
+
+	return c.JSON(fiber.Map{"seasons": seasons})
+}
+
+// Leaderboard handles GET /api/seasons/:id/leaderboard?limit=. The id may
+// be "current"; signed-in callers also get their own standing.
+func (h *ProgressionHandler) Leaderboard(c *fiber.Ctx) error {
+	userID, _ := extractIdentity(c)
+
+	board, err := h.progressionService.Leaderboard(c.Params("id"), userID, c.QueryInt("limit", 0))
+	if err != nil {
+		if errors.Is(err, services.ErrSeasonNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to get leaderboard",
+		})
+	}
+
+	return c.JSON(board)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ShareHandler struct {
+	shareService *services.ShareService
+}
+
+func NewShareHandler(shareService *services.ShareService) *ShareHandler {
+	return &ShareHandler{shareService: shareService}
+}
+
+// CreateShare handles POST /api/share
+func (h *ShareHandler) CreateShare(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	var req dto.CreateShareRequest
+	if err := c.BodyParser(&req); err != nil || req.ChallengeID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "challenge_id is required",
+		})
+	}
+
+	share, err := h.shareService.CreateShare(userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrChallengeNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidPlatform):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to create share",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(share)
+}
+
+// GetShare handles GET /api/share/:code (public).
+// ?via=<platform> attributes the open to the platform the link was seen on.
+func (h *ShareHandler) GetShare(c *fiber.Ctx) error {
+	shared, err := h.shareService.GetSharedChallenge(c.Params("code"), c.Query("via"))
+	if err != nil {
+		if errors.Is(err, services.ErrShareNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to load share",
+		})
+	}
+
+	return c.JSON(shared)
+}
+
+// GetShareCard handles GET /api/share/:code/card.png (public).
+// ?format=story (1080x1920, default) or ?format=link (1200x630).
+func (h *ShareHandler) GetShareCard(c *fiber.Ctx) error {
+	card, err := h.shareService.RenderCard(c.Params("code"), c.Query("format", services.ShareCardStory))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShareNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidCardFormat):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to render share card",
+		})
+	}
+
+	return sendCacheable(c, card.PNG, "image/png", card.RenderedAt, "public, max-age=60")
+}
+
+// ShareAnalytics handles GET /api/admin/analytics/shares
+// Query: group_by=challenge|platform|sharer (default challenge),
+// from/to as YYYY-MM-DD (inclusive, default the last 30 days), limit.
+func (h *ShareHandler) ShareAnalytics(c *fiber.Ctx) error {
+	to := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -30)
+
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "from must be YYYY-MM-DD",
+			})
+		}
+		from = parsed
+	}
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: "to must be YYYY-MM-DD",
+			})
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 500
+	}
+
+	groupBy := c.Query("group_by", "challenge")
+	rows, err := h.shareService.ShareAnalytics(groupBy, from, to, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidShareGroup) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error: true, Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to load share analytics",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"group_by": groupBy,
+		"from":     from.Format("2006-01-02"),
+		"to":       to.AddDate(0, 0, -1).Format("2006-01-02"),
+		"data":     rows,
+		"total":    len(rows),
+	})
+}
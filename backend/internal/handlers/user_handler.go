@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type UserHandler struct {
+	userService        *services.UserService
+	statsService       *services.StatsService
+	personalityService *services.PersonalityService
+}
+
+func NewUserHandler(userService *services.UserService, statsService *services.StatsService, personalityService *services.PersonalityService) *UserHandler {
+	return &UserHandler{userService: userService, statsService: statsService, personalityService: personalityService}
+}
+
+// GetMe handles GET /api/users/me
+func (h *UserHandler) GetMe(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	profile, err := h.userService.GetProfile(userID)
+	if err != nil {
+		return userError(c, err)
+	}
+
+	return c.JSON(profile)
+}
+
+// UpdateMe handles PUT /api/users/me
+func (h *UserHandler) UpdateMe(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Invalid request body",
+		})
+	}
+
+	profile, err := h.userService.UpdateProfile(userID, &req)
+	if err != nil {
+		return userError(c, err)
+	}
+
+	return c.JSON(profile)
+}
+
+// UploadAvatar handles POST /api/users/me/avatar (multipart field "avatar").
+func (h *UserHandler) UploadAvatar(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	fh, err := c.FormFile("avatar")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "avatar file is required",
+		})
+	}
+	if fh.Size > services.MaxAvatarBytes {
+		return userError(c, services.ErrAvatarTooLarge)
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to read uploaded file",
+		})
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, services.MaxAvatarBytes+1))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to read uploaded file",
+		})
+	}
+
+	profile, err := h.userService.SetAvatar(userID, data)
+	if err != nil {
+		return userError(c, err)
+	}
+
+	return c.JSON(profile)
+}
+
+// DeleteAvatar handles DELETE /api/users/me/avatar
+func (h *UserHandler) DeleteAvatar(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	profile, err := h.userService.RemoveAvatar(userID)
+	if err != nil {
+		return userError(c, err)
+	}
+
+	return c.JSON(profile)
+}
+
+// Stats handles GET /api/users/me/stats
+func (h *UserHandler) Stats(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	stats, err := h.statsService.GetUserStats(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to get stats",
+		})
+	}
+
+	return c.JSON(stats)
+}
+
+// Personality handles GET /api/users/me/personality
+func (h *UserHandler) Personality(c *fiber.Ctx) error {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Error: true, Message: "Unauthorized",
+		})
+	}
+
+	personality, err := h.personalityService.GetPersonality(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Error: true, Message: "Failed to get personality summary",
+		})
+	}
+
+	return c.JSON(personality)
+}
+
+func userError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrDisplayNameTaken):
+		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrAvatarTooLarge):
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidDisplayName),
+		errors.Is(err, services.ErrDisplayNameFiltered),
+		errors.Is(err, services.ErrAvatarType):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Error: true, Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Error: true, Message: "Failed to update profile",
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
 	jwtware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -37,12 +38,19 @@ func OptionalAuth(cfg *config.Config) fiber.Handler {
 			return c.Next()
 		}
 
-		// Check for Guest auth: "Guest <device-id>"
+		// Check for Guest auth: "Guest <guest-token>", or the legacy
+		// unsigned "Guest <device-id>" while ALLOW_RAW_GUEST_IDS is on
 		if strings.HasPrefix(authHeader, "Guest ") {
-			guestID := strings.TrimPrefix(authHeader, "Guest ")
-			c.Locals("userID", uuid.Nil)
-			c.Locals("guestID", guestID)
-			return c.Next()
+			credential := strings.TrimPrefix(authHeader, "Guest ")
+			guestID, err := services.ParseGuestToken(cfg, credential, false)
+			if err != nil && cfg.AllowRawGuestIDs && services.IsRawGuestID(credential) {
+				guestID, err = credential, nil
+			}
+			if err == nil {
+				c.Locals("userID", uuid.Nil)
+				c.Locals("guestID", guestID)
+				return c.Next()
+			}
 		}
 
 		// Try Bearer JWT
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestGuestTokenIsNotAnAccessToken(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "test-secret-at-least-32-characters-long", GuestTokenExpiry: time.Hour, AllowRawGuestIDs: true}
+	guestToken, _, err := services.NewGuestToken(cfg, "g_abc")
+	if err != nil {
+		t.Fatalf("NewGuestToken: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/optional", OptionalAuth(cfg), func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("userID").(uuid.UUID)
+		guestID, _ := c.Locals("guestID").(string)
+		return c.SendString(userID.String() + "|" + guestID)
+	})
+	app.Get("/protected", JWTProtected(cfg), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   string
+		status int
+	}{
+		{"guest token as guest", "/optional", "Guest " + guestToken, uuid.Nil.String() + "|g_abc", fiber.StatusOK},
+		{"guest token as bearer on optional auth", "/optional", "Bearer " + guestToken, uuid.Nil.String() + "|", fiber.StatusOK},
+		{"guest token as bearer on protected route", "/protected", "Bearer " + guestToken, "", fiber.StatusUnauthorized},
+		{"raw device ID", "/optional", "Guest device-123", uuid.Nil.String() + "|device-123", fiber.StatusOK},
+		{"raw server-issued ID", "/optional", "Guest g_abc", uuid.Nil.String() + "|", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Authorization", tt.header)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.want == "" {
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if got := string(body); got != tt.want {
+				t.Errorf("identity = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
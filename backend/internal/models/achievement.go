@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Achievement is a badge definition. Its unlock condition is stored as data
+// in Rule, so new badges can be added through the admin API without a
+// deploy; only new rule types need code.
+type Achievement struct {
+	ID          string          `gorm:"primaryKey;size:50" json:"id"` // Stable slug, e.g. "streak_7"
+	Name        string          `gorm:"size:100;not null" json:"name"`
+	Description string          `gorm:"size:255" json:"description"`
+	Icon        string          `gorm:"size:50" json:"icon"` // Icon name in the app's asset catalog
+	Rule        AchievementRule `gorm:"type:jsonb;serializer:json;not null" json:"rule"`
+	Active      bool            `gorm:"not null" json:"active"`
+	SortOrder   int             `gorm:"default:0" json:"sort_order"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// AchievementRule is an unlock condition: a rule type, the value to reach
+// and type-specific filters.
+type AchievementRule struct {
+	Type      string `json:"type"` // "vote_count", "categories_voted", "streak", "minority_run" or "event_count"
+	Threshold int    `json:"threshold"`
+	Event     string `json:"event,omitempty"`      // event_count: the event to count, e.g. "lobby_hosted"
+	Category  string `json:"category,omitempty"`   // vote_count: only votes in this category
+	DailyOnly bool   `json:"daily_only,omitempty"` // vote_count: only votes on daily challenges
+}
+
+// UserAchievement records when a user unlocked an achievement.
+type UserAchievement struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_achievement" json:"user_id"`
+	AchievementID string    `gorm:"size:50;not null;uniqueIndex:idx_user_achievement" json:"achievement_id"`
+	UnlockedAt    time.Time `gorm:"not null" json:"unlocked_at"`
+}
+
+// AchievementCounter counts occurrences of an event for a user, for
+// event_count rules on events that have no table of their own to count.
+type AchievementCounter struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Event     string    `gorm:"size:50;primaryKey" json:"event"`
+	Count     int       `gorm:"not null;default:0" json:"count"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -22,12 +22,14 @@ type Challenge struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
-// Vote represents a user's vote on a challenge
+// Vote represents a user's vote on a challenge.
+// Signed-in users can vote once per challenge (guest votes use uuid.Nil as
+// UserID and are deduplicated by GuestID in the service instead).
 type Vote struct {
 	ID          uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	UserID      uuid.UUID      `gorm:"type:uuid;index" json:"user_id"`
+	UserID      uuid.UUID      `gorm:"type:uuid;index;uniqueIndex:idx_votes_user_challenge,where:user_id <> '00000000-0000-0000-0000-000000000000' AND deleted_at IS NULL" json:"user_id"`
 	GuestID     string         `gorm:"size:255;index" json:"guest_id"`
-	ChallengeID uuid.UUID      `gorm:"type:uuid;not null;index" json:"challenge_id"`
+	ChallengeID uuid.UUID      `gorm:"type:uuid;not null;index;uniqueIndex:idx_votes_user_challenge" json:"challenge_id"`
 	Choice      string         `gorm:"size:1;not null" json:"choice"` // "A" or "B"
 	CreatedAt   time.Time      `json:"created_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataExport is an asynchronous GDPR/KVKK export of everything stored about
+// a user. The zipped archive lives in blob storage until ExpiresAt.
+type DataExport struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status      string     `gorm:"size:20;not null;default:'pending';index" json:"status"` // pending, processing, ready, failed, expired
+	BlobKey     string     `gorm:"size:255" json:"-"`
+	SizeBytes   int64      `json:"size_bytes"`
+	Error       string     `gorm:"size:500" json:"-"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeletionReceipt records that an account was purged, without keeping any
+// personal data. UserHash is the SHA-256 of the former user ID so a
+// receipt can be matched to a deletion request on inquiry.
+type DeletionReceipt struct {
+	ID          uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserHash    string           `gorm:"size:64;not null;uniqueIndex" json:"user_hash"`
+	RequestedAt *time.Time       `json:"requested_at"` // Nil for accounts soft-deleted before the grace period existed
+	PurgedAt    time.Time        `gorm:"not null" json:"purged_at"`
+	Counts      map[string]int64 `gorm:"type:jsonb;serializer:json" json:"counts"` // Rows deleted or anonymized per kind
+	CreatedAt   time.Time        `json:"created_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceToken is a push token registered by a signed-in user or a guest
+// (UserID is uuid.Nil for guests). A token belongs to whoever registered
+// it last.
+type DeviceToken struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Token          string    `gorm:"size:255;not null;uniqueIndex" json:"token"`
+	Provider       string    `gorm:"size:10;not null" json:"provider"` // "expo" or "apns"
+	Platform       string    `gorm:"size:10" json:"platform"`          // "ios" or "android"
+	UserID         uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	GuestID        string    `gorm:"size:64;index" json:"guest_id,omitempty"`
+	Timezone       string    `gorm:"size:64;not null;index" json:"timezone"` // IANA name, e.g. "Europe/Istanbul"
+	DailyReminders bool      `gorm:"not null" json:"daily_reminders"`
+	LastDailyPush  string    `gorm:"size:10" json:"-"` // Local date ("2006-01-02") of the last daily reminder
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// PushTicket is a push a provider accepted for later delivery. Delivery
+// failures, such as an uninstalled app, only show up in the ticket's
+// receipt, which the receipts job fetches once it is ready.
+type PushTicket struct {
+	ID        string    `gorm:"size:64;primaryKey" json:"id"` // The provider's ticket ID
+	Provider  string    `gorm:"size:10;not null" json:"provider"`
+	Token     string    `gorm:"size:255;not null" json:"token"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Friendship links two users. It starts as a request from RequesterID to
+// AddresseeID and becomes mutual once accepted. PairKey is the two IDs in
+// sorted order, so a pair can only ever have one row.
+type Friendship struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RequesterID uuid.UUID  `gorm:"type:uuid;not null;index" json:"requester_id"`
+	AddresseeID uuid.UUID  `gorm:"type:uuid;not null;index" json:"addressee_id"`
+	PairKey     string     `gorm:"size:73;not null;uniqueIndex" json:"-"`
+	Status      string     `gorm:"size:20;not null;default:'pending';index" json:"status"` // "pending", "accepted" or "declined"
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (Friendship) TableName() string {
+	return "friendships"
+}
+
+// FriendPairKey returns the PairKey for two users, in either order.
+func FriendPairKey(a, b uuid.UUID) string {
+	x, y := a.String(), b.String()
+	if x > y {
+		x, y = y, x
+	}
+	return x + ":" + y
+}
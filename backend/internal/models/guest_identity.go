@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// GuestIdentity is a server-issued guest ID. Guests vote under it via a
+// signed guest token; the row lets issuance be throttled per client IP.
+type GuestIdentity struct {
+	ID        string    `gorm:"primaryKey;size:64" json:"id"`
+	IPHash    string    `gorm:"size:64;index" json:"-"`
+	Legacy    bool      `gorm:"default:false" json:"legacy"` // Adopted from a raw "Guest <device-id>" ID
+	CreatedAt time.Time `json:"created_at"`
+}
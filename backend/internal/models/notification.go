@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is an entry in a user's in-app inbox. Rows past ExpiresAt
+// are hidden and removed by the cleanup job.
+type Notification struct {
+	ID        uuid.UUID         `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID         `gorm:"type:uuid;not null;index:idx_notifications_user_created,priority:1" json:"user_id"`
+	Type      string            `gorm:"size:50;not null" json:"type"` // e.g. "friend_request", "achievement_unlocked"
+	Title     string            `gorm:"size:200;not null" json:"title"`
+	Body      string            `gorm:"size:1000" json:"body"`
+	Payload   map[string]string `gorm:"type:jsonb;serializer:json" json:"payload,omitempty"`
+	ReadAt    *time.Time        `json:"read_at,omitempty"`
+	ExpiresAt time.Time         `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time         `gorm:"index:idx_notifications_user_created,priority:2,sort:desc" json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalitySummary caches a user's generated personality write-up until
+// they have cast enough new votes to warrant a fresh one.
+type PersonalitySummary struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Summary   string    `gorm:"type:text;not null" json:"summary"`
+	Source    string    `gorm:"size:20;not null" json:"source"` // "ai" or "fallback"
+	VoteCount int       `gorm:"default:0" json:"vote_count"`    // Votes analyzed when generated
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// XPEvent is one XP award. RefKey identifies what earned it (a challenge,
+// a day, a lobby) so the same action can't be rewarded twice.
+type XPEvent struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_xp_events_ref;index:idx_xp_events_user_created,priority:1" json:"user_id"`
+	Source    string    `gorm:"size:20;not null;uniqueIndex:idx_xp_events_ref" json:"source"` // "vote", "daily", "share" or "lobby_win"
+	RefKey    string    `gorm:"size:64;not null;uniqueIndex:idx_xp_events_ref" json:"ref_key"`
+	Amount    int       `gorm:"not null" json:"amount"`
+	SeasonID  string    `gorm:"size:20;not null" json:"season_id"`
+	CreatedAt time.Time `gorm:"index:idx_xp_events_user_created,priority:2" json:"created_at"`
+}
+
+// UserXP is a user's lifetime XP total; the level is derived from it.
+type UserXP struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	TotalXP   int       `gorm:"not null;default:0" json:"total_xp"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (UserXP) TableName() string {
+	return "user_xp"
+}
+
+// Season is one ranked ladder period. Seasons are created when first
+// needed and finalized by the rollover job once they end.
+type Season struct {
+	ID          string     `gorm:"primaryKey;size:20" json:"id"` // Start date, e.g. "2026-10-01"
+	StartsAt    time.Time  `gorm:"not null" json:"starts_at"`
+	EndsAt      time.Time  `gorm:"not null;index" json:"ends_at"`
+	FinalizedAt *time.Time `json:"finalized_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// SeasonScore is a user's XP within one season. FinalRank is set when the
+// season is finalized and kept as ladder history.
+type SeasonScore struct {
+	SeasonID  string    `gorm:"primaryKey;size:20;index:idx_season_scores_xp,priority:1" json:"season_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	XP        int       `gorm:"not null;default:0;index:idx_season_scores_xp,priority:2,sort:desc" json:"xp"`
+	FinalRank *int      `json:"final_rank,omitempty"`
+	Prize     string    `gorm:"size:100" json:"prize,omitempty"` // Entitlement granted for the final rank
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EntitlementGrant is an entitlement given outside the app stores, such as
+// a season prize. It counts like a subscription entitlement until it expires.
+type EntitlementGrant struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Entitlement string    `gorm:"size:100;not null" json:"entitlement"`
+	Source      string    `gorm:"size:100;not null" json:"source"` // e.g. "season:2026-10-01"
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// QuestionPack is a curated, downloadable set of challenges (offline packs).
+// Version increases every time the pack's metadata or challenge list changes
+// so clients can tell whether their cached copy is stale.
+type QuestionPack struct {
+	ID             uuid.UUID          `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name           string             `gorm:"size:100;not null" json:"name"`
+	Description    string             `gorm:"type:text" json:"description"`
+	Category       string             `gorm:"size:50;not null;index" json:"category"`
+	IsPremium      bool               `gorm:"default:false" json:"is_premium"`
+	ChallengeCount int                `gorm:"default:0" json:"challenge_count"`
+	Version        int                `gorm:"not null;default:1" json:"version"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt     `gorm:"index" json:"-"`
+	Items          []QuestionPackItem `gorm:"foreignKey:PackID" json:"-"`
+}
+
+// QuestionPackItem places a challenge at a position inside a pack.
+// Items are never hard-deleted while the pack exists: a removed item keeps
+// its row with RemovedVersion set, so delta downloads can tell clients to
+// drop it. AddedVersion/UpdatedVersion record the pack version in which the
+// item last appeared or moved.
+type QuestionPackItem struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	PackID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_pack_item_challenge" json:"pack_id"`
+	ChallengeID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_pack_item_challenge;index" json:"challenge_id"`
+	SortOrder      int       `gorm:"default:0" json:"sort_order"`
+	AddedVersion   int       `gorm:"not null;default:1" json:"added_version"`
+	UpdatedVersion int       `gorm:"not null;default:1" json:"updated_version"`
+	RemovedVersion int       `gorm:"not null;default:0;index" json:"removed_version,omitempty"` // 0 while the item is live
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Challenge      Challenge `gorm:"foreignKey:ChallengeID" json:"challenge"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Share is a user's shareable link to a challenge. Each user gets one short
+// code per challenge, reused across platforms. Shares outlive a purged
+// account with UserID set to uuid.Nil, so their links and analytics stay.
+type Share struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Code        string    `gorm:"size:16;not null;uniqueIndex" json:"code"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_shares_owner_challenge,where:user_id <> '00000000-0000-0000-0000-000000000000'" json:"user_id"`
+	ChallengeID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_shares_owner_challenge;index" json:"challenge_id"`
+	Platform    string    `gorm:"size:20" json:"platform"` // Platform of the first share; later shares are in ShareEvent
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Challenge   Challenge `gorm:"foreignKey:ChallengeID" json:"-"`
+}
+
+// ShareEvent is an analytics record: the share being created or re-shared
+// on a platform, or the public link being opened.
+type ShareEvent struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ShareID   uuid.UUID `gorm:"type:uuid;not null;index" json:"share_id"`
+	Type      string    `gorm:"size:20;not null;index" json:"type"` // "shared" or "opened"
+	Platform  string    `gorm:"size:20;index" json:"platform"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// ShareInstall attributes a sign-up to the share link that led to it. It
+// outlives the account: a purge clears UserID and records in Activated
+// whether the user had voted, so install analytics don't change.
+type ShareInstall struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ShareID   uuid.UUID `gorm:"type:uuid;not null;index" json:"share_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	Activated bool      `gorm:"not null;default:false" json:"activated"` // Voted before the account was purged
+	CreatedAt time.Time `gorm:"index" json:"created_at"`                 // When the account signed up
+}
@@ -12,6 +12,7 @@ type Subscription struct {
 	RevenueCatID       string    `gorm:"index;size:255" json:"revenuecat_id"`
 	ProductID          string    `gorm:"size:255" json:"product_id"`
 	Status             string    `gorm:"not null;default:'inactive';size:50" json:"status"`
+	EntitlementIDs     []string  `gorm:"type:jsonb;serializer:json" json:"entitlement_ids"` // RevenueCat entitlements granted by this subscription
 	CurrentPeriodStart time.Time `json:"current_period_start"`
 	CurrentPeriodEnd   time.Time `json:"current_period_end"`
 	CreatedAt          time.Time `json:"created_at"`
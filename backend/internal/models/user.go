@@ -8,11 +8,20 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	Email     string         `gorm:"uniqueIndex;not null;size:255" json:"email"`
-	Password  string         `gorm:"not null" json:"-"`
-	Role      string         `gorm:"size:20;default:'user'" json:"role"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                  uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Email               string         `gorm:"uniqueIndex;not null;size:255" json:"email"`
+	Password            string         `gorm:"not null" json:"-"`
+	Role                string         `gorm:"size:20;default:'user'" json:"role"`
+	EmailVerifiedAt     *time.Time     `json:"email_verified_at,omitempty"`
+	Locale              string         `gorm:"size:10;default:'en'" json:"locale"` // Language for emails and notifications
+	DisplayName         string         `gorm:"size:30" json:"display_name"`
+	DisplayNameKey      *string        `gorm:"size:30;uniqueIndex" json:"-"`               // Lower-cased DisplayName; nil when unset
+	AvatarKey           string         `gorm:"size:255" json:"-"`                          // Blob storage key of the current avatar
+	FriendCode          *string        `gorm:"size:12;uniqueIndex" json:"-"`               // Share-able code for adding friends; allocated on first use
+	ShowVotesToFriends  bool           `gorm:"default:false" json:"show_votes_to_friends"` // Opt-in: friends see this user's picks
+	DeletionRequestedAt *time.Time     `json:"-"`                                          // Set while the account is in its deletion grace period
+	PurgeAfter          *time.Time     `gorm:"index" json:"-"`                             // When the purge job hard-deletes the account
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserStats caches a user's vote-derived stats. Votes, offline syncs and
+// guest merges mark it Dirty; the next read brings it up to date
+// incrementally from the votes cast after LastVoteAt/LastVoteID. It is
+// rebuilt from scratch periodically so majority judgments reflect settled
+// results, and whenever votes may have landed behind the watermark.
+type UserStats struct {
+	ID              uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID          uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	TotalVotes      int              `gorm:"default:0" json:"total_votes"`
+	CategoryCounts  map[string]int   `gorm:"type:jsonb;serializer:json" json:"category_counts"`
+	DecidedVotes    int              `gorm:"default:0" json:"decided_votes"`  // Votes on challenges with a clear majority
+	MajorityVotes   int              `gorm:"default:0" json:"majority_votes"` // Of those, votes siding with it
+	ContrarianPicks []ContrarianPick `gorm:"type:jsonb;serializer:json" json:"contrarian_picks"`
+	DailyVotes      int              `gorm:"default:0" json:"daily_votes"`
+	MinorityRun     int              `gorm:"default:0" json:"minority_run"` // Latest votes in a row that sided with the minority
+	LastVoteAt      time.Time        `json:"last_vote_at"`                  // Watermark: newest vote folded in
+	LastVoteID      uuid.UUID        `gorm:"type:uuid" json:"last_vote_id"`
+	RebuiltAt       time.Time        `json:"rebuilt_at"`
+	Dirty           bool             `gorm:"not null;default:false" json:"-"` // Votes changed since the last fold
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// ContrarianPick is a vote where the user sided with a small minority.
+type ContrarianPick struct {
+	ChallengeID  uuid.UUID `json:"challenge_id"`
+	Choice       string    `json:"choice"`
+	SharePercent int       `json:"share_percent"` // Share of other voters who picked the same
+}
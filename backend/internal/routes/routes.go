@@ -4,6 +4,7 @@ import (
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/handlers"
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/middleware"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -13,12 +14,38 @@ func Setup(
 	cfg *config.Config,
 	db *gorm.DB,
 	authHandler *handlers.AuthHandler,
+	emailHandler *handlers.EmailHandler,
 	healthHandler *handlers.HealthHandler,
 	webhookHandler *handlers.WebhookHandler,
 	moderationHandler *handlers.ModerationHandler,
 	challengeHandler *handlers.ChallengeHandler,
 	legalHandler *handlers.LegalHandler,
+	feedHandler *handlers.FeedHandler,
+	packHandler *handlers.PackHandler,
+	shareHandler *handlers.ShareHandler,
+	landingHandler *handlers.LandingHandler,
+	userHandler *handlers.UserHandler,
+	exportHandler *handlers.ExportHandler,
+	friendHandler *handlers.FriendHandler,
+	achievementHandler *handlers.AchievementHandler,
+	progressionHandler *handlers.ProgressionHandler,
+	notificationHandler *handlers.NotificationHandler,
+	deviceHandler *handlers.DeviceHandler,
+	subscriptionService *services.SubscriptionService,
+	packService *services.PackService,
 ) {
+	// Syndication feeds (public)
+	feeds := app.Group("/feeds")
+	feeds.Get("/daily.rss", feedHandler.DailyRSS)
+	feeds.Get("/daily.atom", feedHandler.DailyAtom)
+	feeds.Get("/daily.json", feedHandler.DailyJSON)
+
+	// Landing pages for shared links (public HTML with Open Graph tags)
+	app.Get("/s/:code", landingHandler.SharePage)
+	app.Get("/daily/:date", landingHandler.DailyPage)
+	app.Get("/daily/:date/card.png", landingHandler.DailyCard)
+	app.Get("/oembed", landingHandler.OEmbed)
+
 	api := app.Group("/api")
 
 	// Health
@@ -33,29 +60,105 @@ func Setup(
 	auth.Post("/register", authHandler.Register)
 	auth.Post("/login", authHandler.Login)
 	auth.Post("/refresh", authHandler.Refresh)
+	auth.Post("/guest", authHandler.Guest)
 	auth.Post("/apple", authHandler.AppleSignIn) // Sign in with Apple (Guideline 4.8)
+	auth.Get("/email/verify", emailHandler.VerifyLink)
+	auth.Post("/email/verify", emailHandler.Verify)
+	auth.Post("/email/verify/link", emailHandler.ConfirmLink)
+	auth.Post("/password/forgot", authHandler.ForgotPassword)
+	auth.Get("/password/reset", authHandler.ResetLink)
+	auth.Post("/password/reset", authHandler.ResetPassword)
+	auth.Post("/password/reset/link", authHandler.ConfirmResetLink)
 
-	// Auth (protected)
+	// Webhooks (verified by auth header, not JWT)
+	webhooks := api.Group("/webhooks")
+	webhooks.Post("/revenuecat", webhookHandler.HandleRevenueCat)
+
+	// Challenges - public with optional auth (daily + vote + category + random).
+	// Categories listed in PREMIUM_CATEGORIES answer 402 without the entitlement.
+	optionalAuth := api.Group("/challenges", middleware.OptionalAuth(cfg))
+	optionalAuth.Get("/daily", challengeHandler.GetDailyChallenge)
+	optionalAuth.Post("/vote", challengeHandler.Vote)
+	optionalAuth.Get("/random", challengeHandler.GetRandom)
+	optionalAuth.Get("/category/:category", middleware.RequireEntitlement(subscriptionService, middleware.CategoryEntitlement(subscriptionService, "category")), challengeHandler.GetByCategory)
+
+	// Question packs - public listing with optional auth (entitlement flags)
+	packs := api.Group("/packs", middleware.OptionalAuth(cfg))
+	packs.Get("/", packHandler.ListPacks)
+	packs.Get("/public-key", packHandler.PublicKey) // Ed25519 key for verifying .wypack files
+	packs.Get("/:id/download", middleware.RequireEntitlement(subscriptionService, middleware.PackEntitlement(packService, "id")), packHandler.DownloadPack)
+
+	// Push device registry - users and guests (guest token via optional auth)
+	devices := api.Group("/devices", middleware.OptionalAuth(cfg))
+	devices.Post("/", deviceHandler.Register)
+	devices.Delete("/", deviceHandler.Unregister)
+
+	// Ranked seasons - public ladders with optional auth (caller's own rank)
+	seasons := api.Group("/seasons", middleware.OptionalAuth(cfg))
+	seasons.Get("/", progressionHandler.ListSeasons)
+	seasons.Get("/:id/leaderboard", progressionHandler.Leaderboard)
+
+	// Share links (public viewer)
+	api.Get("/share/:code", shareHandler.GetShare)
+	api.Get("/share/:code/card.png", shareHandler.GetShareCard)
+
+	// Data export downloads (public, authorized by a signed expiring URL)
+	api.Get("/exports/:id/download", exportHandler.Download)
+
+	// Protected routes. Fiber applies group middleware to every route registered
+	// later under the same prefix, so public routes must stay above this line.
 	protected := api.Group("", middleware.JWTProtected(cfg))
+
+	// Auth (protected)
 	protected.Post("/auth/logout", authHandler.Logout)
 	protected.Delete("/auth/account", authHandler.DeleteAccount) // Account deletion (Guideline 5.1.1)
+	protected.Post("/auth/email/resend", emailHandler.Resend)
+	protected.Post("/auth/email/change", emailHandler.ChangeEmail)
 
 	// Moderation - User endpoints (protected)
 	protected.Post("/reports", moderationHandler.CreateReport)     // Report content (Guideline 1.2)
 	protected.Post("/blocks", moderationHandler.BlockUser)         // Block user (Guideline 1.2)
 	protected.Delete("/blocks/:id", moderationHandler.UnblockUser) // Unblock user
 
-	// Challenges - public with optional auth (daily + vote + category + random)
-	optionalAuth := api.Group("/challenges", middleware.OptionalAuth(cfg))
-	optionalAuth.Get("/daily", challengeHandler.GetDailyChallenge)
-	optionalAuth.Post("/vote", challengeHandler.Vote)
-	optionalAuth.Get("/random", challengeHandler.GetRandom)
-	optionalAuth.Get("/category/:category", challengeHandler.GetByCategory)
+	// Profile (protected)
+	protected.Get("/users/me", userHandler.GetMe)
+	protected.Get("/users/me/stats", userHandler.Stats)
+	protected.Get("/users/me/personality", userHandler.Personality)
+	protected.Get("/users/me/achievements", achievementHandler.ListMine)
+	protected.Get("/users/me/progress", progressionHandler.MyProgress)
+	protected.Get("/users/me/seasons", progressionHandler.MySeasons)
+	protected.Post("/users/me/exports", exportHandler.RequestExport)
+	protected.Get("/users/me/exports", exportHandler.ListExports)
+	protected.Get("/users/me/exports/:id", exportHandler.GetExport)
+	protected.Put("/users/me", userHandler.UpdateMe)
+	protected.Post("/users/me/avatar", userHandler.UploadAvatar)
+	protected.Delete("/users/me/avatar", userHandler.DeleteAvatar)
+
+	// Friends (protected)
+	protected.Get("/friends", friendHandler.ListFriends)
+	protected.Get("/friends/search", friendHandler.Search)
+	protected.Get("/friends/code", friendHandler.MyCode)
+	protected.Get("/friends/code/:code", friendHandler.LookupCode)
+	protected.Get("/friends/requests", friendHandler.ListRequests)
+	protected.Post("/friends/requests", friendHandler.SendRequest)
+	protected.Post("/friends/requests/:id/accept", friendHandler.AcceptRequest)
+	protected.Post("/friends/requests/:id/decline", friendHandler.DeclineRequest)
+	protected.Delete("/friends/requests/:id", friendHandler.CancelRequest)
+	protected.Delete("/friends/:id", friendHandler.RemoveFriend)
+
+	// Notifications inbox (protected)
+	protected.Get("/notifications", notificationHandler.List)
+	protected.Post("/notifications/read-all", notificationHandler.MarkAllRead)
+	protected.Post("/notifications/:id/read", notificationHandler.MarkRead)
+
+	// Sharing (protected)
+	protected.Post("/share", shareHandler.CreateShare)
 
 	// Challenges - protected (stats + history require auth)
 	protectedChallenges := protected.Group("/challenges")
 	protectedChallenges.Get("/stats", challengeHandler.GetStats)
 	protectedChallenges.Get("/history", challengeHandler.GetHistory)
+	protectedChallenges.Post("/sync", challengeHandler.SyncVotes) // Offline vote sync
 
 	// Admin panel (protected + admin role check)
 	admin := api.Group("/admin", middleware.JWTProtected(cfg), middleware.AdminOnly(db))
@@ -66,7 +169,19 @@ func Setup(
 	admin.Post("/challenges/generate", challengeHandler.GenerateQuestions)
 	admin.Post("/challenges/generate-all", challengeHandler.GenerateAllCategories)
 
-	// Webhooks (verified by auth header, not JWT)
-	webhooks := api.Group("/webhooks")
-	webhooks.Post("/revenuecat", webhookHandler.HandleRevenueCat)
+	// Question pack management
+	admin.Get("/packs", packHandler.AdminListPacks)
+	admin.Post("/packs", packHandler.CreatePack)
+	admin.Post("/packs/import", packHandler.ImportPack)
+	admin.Get("/packs/:id", packHandler.AdminGetPack)
+	admin.Put("/packs/:id", packHandler.UpdatePack)
+	admin.Delete("/packs/:id", packHandler.DeletePack)
+	admin.Get("/packs/:id/export", packHandler.ExportPack)
+
+	// Achievement definitions
+	admin.Get("/achievements", achievementHandler.AdminList)
+	admin.Put("/achievements/:id", achievementHandler.AdminUpsert)
+
+	// Analytics
+	admin.Get("/analytics/shares", shareHandler.ShareAnalytics)
 }
@@ -0,0 +1,458 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Events that can unlock achievements.
+const (
+	EventVoteCast      = "vote_cast"
+	EventStreakUpdated = "streak_updated"
+	EventShareCreated  = "share_created"
+	EventLobbyHosted   = "lobby_hosted"
+	EventLobbyFinished = "lobby_finished"
+)
+
+var (
+	ErrAchievementNotFound    = errors.New("achievement not found")
+	ErrInvalidAchievement     = errors.New("achievement id must be 2-50 characters of a-z, 0-9 or '_', and name is required")
+	ErrInvalidAchievementRule = errors.New("invalid achievement rule")
+)
+
+var achievementIDPattern = regexp.MustCompile(`^[a-z0-9_]{2,50}$`)
+
+// countedEvents are the events kept in AchievementCounter. Vote rules are
+// evaluated from the user's cached stats and streaks from their own table.
+var countedEvents = map[string]bool{
+	EventShareCreated:  true,
+	EventLobbyHosted:   true,
+	EventLobbyFinished: true,
+}
+
+// coreCategories are the categories of the built-in challenge pool, the
+// ones "every category" rules cover. Categories that generated or imported
+// challenges add come and go, so they don't raise the bar.
+var coreCategories = func() []string {
+	seen := map[string]bool{}
+	var categories []string
+	for _, c := range models.DailyChallenges {
+		if !seen[c.Category] {
+			seen[c.Category] = true
+			categories = append(categories, c.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}()
+
+// playableCategories are the core categories outside locked, i.e. the
+// ones a user without those entitlements can vote in.
+func playableCategories(locked []string) []string {
+	skip := map[string]bool{}
+	for _, c := range locked {
+		skip[c] = true
+	}
+	playable := make([]string, 0, len(coreCategories))
+	for _, c := range coreCategories {
+		if !skip[c] {
+			playable = append(playable, c)
+		}
+	}
+	return playable
+}
+
+// achievementEvaluator computes a user's current value and target for one
+// rule type. triggers lists the events that can change the value; nil
+// means the rule's own Event.
+type achievementEvaluator struct {
+	triggers []string
+	value    func(p *achievementProgress, rule *models.AchievementRule) (value, target int, err error)
+}
+
+var achievementEvaluators = map[string]achievementEvaluator{
+	// Votes cast, optionally only in one category or on daily challenges
+	"vote_count": {
+		triggers: []string{EventVoteCast},
+		value: func(p *achievementProgress, rule *models.AchievementRule) (int, int, error) {
+			stats, err := p.userStats()
+			if err != nil {
+				return 0, 0, err
+			}
+			switch {
+			case rule.Category != "":
+				return stats.CategoryCounts[rule.Category], rule.Threshold, nil
+			case rule.DailyOnly:
+				return stats.DailyVotes, rule.Threshold, nil
+			}
+			return stats.TotalVotes, rule.Threshold, nil
+		},
+	},
+	// Distinct categories voted in; a threshold of 0 means every core
+	// category the user can vote in
+	"categories_voted": {
+		triggers: []string{EventVoteCast},
+		value: func(p *achievementProgress, rule *models.AchievementRule) (int, int, error) {
+			stats, err := p.userStats()
+			if err != nil {
+				return 0, 0, err
+			}
+			if rule.Threshold > 0 {
+				return len(stats.CategoryCounts), rule.Threshold, nil
+			}
+			// Every category the user can vote in
+			playable := playableCategories(p.s.subscriptions.LockedCategories(p.userID))
+			voted := 0
+			for _, c := range playable {
+				if stats.CategoryCounts[c] > 0 {
+					voted++
+				}
+			}
+			return voted, len(playable), nil
+		},
+	},
+	// Longest daily voting streak
+	"streak": {
+		triggers: []string{EventStreakUpdated},
+		value: func(p *achievementProgress, rule *models.AchievementRule) (int, int, error) {
+			streak, err := p.longestStreak()
+			return streak, rule.Threshold, err
+		},
+	},
+	// Latest votes in a row that sided with the minority, judged by each
+	// challenge's split when the vote was folded into the user's stats.
+	// Ties break the run.
+	"minority_run": {
+		triggers: []string{EventVoteCast},
+		value: func(p *achievementProgress, rule *models.AchievementRule) (int, int, error) {
+			stats, err := p.userStats()
+			if err != nil {
+				return 0, 0, err
+			}
+			return stats.MinorityRun, rule.Threshold, nil
+		},
+	},
+	// Occurrences of a counted event, e.g. shares created or lobbies hosted
+	"event_count": {
+		value: func(p *achievementProgress, rule *models.AchievementRule) (int, int, error) {
+			count, err := p.eventCount(rule.Event)
+			return count, rule.Threshold, err
+		},
+	},
+}
+
+// achievementProgress is what one user's rules are evaluated against: their
+// cached stats, streak and event counters, each loaded at most once.
+type achievementProgress struct {
+	s        *AchievementService
+	userID   uuid.UUID
+	stats    *models.UserStats
+	streak   *int
+	counters map[string]int
+}
+
+func (p *achievementProgress) userStats() (*models.UserStats, error) {
+	if p.stats == nil {
+		stats, err := p.s.stats.UserStats(p.userID)
+		if err != nil {
+			return nil, err
+		}
+		p.stats = stats
+	}
+	return p.stats, nil
+}
+
+func (p *achievementProgress) longestStreak() (int, error) {
+	if p.streak == nil {
+		var streak models.ChallengeStreak
+		if err := p.s.db.Where("user_id = ?", p.userID).First(&streak).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, err
+		}
+		p.streak = &streak.LongestStreak
+	}
+	return *p.streak, nil
+}
+
+func (p *achievementProgress) eventCount(event string) (int, error) {
+	if p.counters == nil {
+		var counters []models.AchievementCounter
+		if err := p.s.db.Where("user_id = ?", p.userID).Find(&counters).Error; err != nil {
+			return 0, err
+		}
+		p.counters = make(map[string]int, len(counters))
+		for _, c := range counters {
+			p.counters[c.Event] = c.Count
+		}
+	}
+	return p.counters[event], nil
+}
+
+// defaultAchievements are seeded on startup. Existing rows are left alone
+// so changes made through the admin API stick.
+var defaultAchievements = []models.Achievement{
+	{ID: "first_vote", Name: "First Pick", Description: "Cast your first vote", Icon: "hand", Rule: models.AchievementRule{Type: "vote_count", Threshold: 1}, Active: true, SortOrder: 10},
+	{ID: "votes_100", Name: "Centurion", Description: "Vote 100 times", Icon: "hundred", Rule: models.AchievementRule{Type: "vote_count", Threshold: 100}, Active: true, SortOrder: 20},
+	{ID: "votes_1000", Name: "Decider", Description: "Vote 1,000 times", Icon: "gavel", Rule: models.AchievementRule{Type: "vote_count", Threshold: 1000}, Active: true, SortOrder: 30},
+	{ID: "daily_30", Name: "Daily Regular", Description: "Answer 30 daily questions", Icon: "calendar", Rule: models.AchievementRule{Type: "vote_count", Threshold: 30, DailyOnly: true}, Active: true, SortOrder: 40},
+	{ID: "all_categories", Name: "Explorer", Description: "Vote in every category", Icon: "compass", Rule: models.AchievementRule{Type: "categories_voted"}, Active: true, SortOrder: 50},
+	{ID: "streak_7", Name: "On Fire", Description: "Keep a 7-day voting streak", Icon: "flame", Rule: models.AchievementRule{Type: "streak", Threshold: 7}, Active: true, SortOrder: 60},
+	{ID: "streak_30", Name: "Unstoppable", Description: "Keep a 30-day voting streak", Icon: "rocket", Rule: models.AchievementRule{Type: "streak", Threshold: 30}, Active: true, SortOrder: 70},
+	{ID: "minority_10", Name: "Lone Wolf", Description: "Agree with the minority 10 times in a row", Icon: "wolf", Rule: models.AchievementRule{Type: "minority_run", Threshold: 10}, Active: true, SortOrder: 80},
+	{ID: "first_share", Name: "Spread the Word", Description: "Share a question", Icon: "megaphone", Rule: models.AchievementRule{Type: "event_count", Event: EventShareCreated, Threshold: 1}, Active: true, SortOrder: 90},
+	{ID: "shares_10", Name: "Influencer", Description: "Share 10 questions", Icon: "star", Rule: models.AchievementRule{Type: "event_count", Event: EventShareCreated, Threshold: 10}, Active: true, SortOrder: 100},
+	// Inactive until multiplayer lobbies emit lobby events
+	{ID: "lobbies_hosted_5", Name: "Host with the Most", Description: "Host 5 lobbies", Icon: "crown", Rule: models.AchievementRule{Type: "event_count", Event: EventLobbyHosted, Threshold: 5}, SortOrder: 110},
+}
+
+// AchievementService evaluates achievement rules when events happen and
+// records unlocks.
+type AchievementService struct {
+	db            *gorm.DB
+	stats         *StatsService
+	subscriptions *SubscriptionService
+	notifier      Notifier
+}
+
+func NewAchievementService(db *gorm.DB, stats *StatsService, subs *SubscriptionService, notifier Notifier) *AchievementService {
+	return &AchievementService{db: db, stats: stats, subscriptions: subs, notifier: notifier}
+}
+
+// SeedDefaults inserts the built-in achievements that don't exist yet.
+func (s *AchievementService) SeedDefaults() error {
+	for _, a := range defaultAchievements {
+		if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&a).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record notes that events happened for a user and unlocks any
+// achievements they now qualify for. It is safe to call concurrently and
+// from a goroutine; failures are logged.
+func (s *AchievementService) Record(userID uuid.UUID, events ...string) {
+	if userID == uuid.Nil || len(events) == 0 {
+		return
+	}
+
+	for _, event := range events {
+		if !countedEvents[event] {
+			continue
+		}
+		if err := s.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("achievement_counters.count + 1"), "updated_at": time.Now()}),
+		}).Create(&models.AchievementCounter{UserID: userID, Event: event, Count: 1}).Error; err != nil {
+			log.Printf("Failed to count %s for user %s: %v", event, userID, err)
+		}
+	}
+
+	var candidates []models.Achievement
+	if err := s.db.Where("active").
+		Where("id NOT IN (?)", s.db.Model(&models.UserAchievement{}).Select("achievement_id").Where("user_id = ?", userID)).
+		Find(&candidates).Error; err != nil {
+		log.Printf("Failed to load achievements for user %s: %v", userID, err)
+		return
+	}
+
+	progress := &achievementProgress{s: s, userID: userID}
+	for i := range candidates {
+		a := &candidates[i]
+		eval, ok := achievementEvaluators[a.Rule.Type]
+		if !ok || !triggeredBy(eval, &a.Rule, events) {
+			continue
+		}
+		value, target, err := eval.value(progress, &a.Rule)
+		if err != nil {
+			log.Printf("Failed to evaluate achievement %s for user %s: %v", a.ID, userID, err)
+			continue
+		}
+		if target > 0 && value >= target {
+			s.unlock(userID, a)
+		}
+	}
+}
+
+// ListForUser returns every active achievement, plus retired ones the user
+// already holds, with unlock times and progress.
+func (s *AchievementService) ListForUser(userID uuid.UUID) (*dto.AchievementsResponse, error) {
+	var unlocks []models.UserAchievement
+	if err := s.db.Where("user_id = ?", userID).Find(&unlocks).Error; err != nil {
+		return nil, err
+	}
+	unlockedAt := make(map[string]time.Time, len(unlocks))
+	ids := make([]string, len(unlocks))
+	for i, u := range unlocks {
+		unlockedAt[u.AchievementID] = u.UnlockedAt
+		ids[i] = u.AchievementID
+	}
+
+	q := s.db.Where("active")
+	if len(ids) > 0 {
+		q = s.db.Where("active OR id IN ?", ids)
+	}
+	var achievements []models.Achievement
+	if err := q.Order("sort_order, id").Find(&achievements).Error; err != nil {
+		return nil, err
+	}
+
+	progress := &achievementProgress{s: s, userID: userID}
+	resp := &dto.AchievementsResponse{Achievements: make([]dto.AchievementResponse, 0, len(achievements))}
+	for i := range achievements {
+		a := &achievements[i]
+		item := dto.AchievementResponse{
+			ID:          a.ID,
+			Name:        a.Name,
+			Description: a.Description,
+			Icon:        a.Icon,
+		}
+
+		if eval, ok := achievementEvaluators[a.Rule.Type]; ok {
+			value, target, err := eval.value(progress, &a.Rule)
+			if err != nil {
+				return nil, err
+			}
+			item.Progress, item.Target = value, target
+		}
+		if at, ok := unlockedAt[a.ID]; ok {
+			at := at
+			item.Unlocked = true
+			item.UnlockedAt = &at
+			resp.Unlocked++
+			// Progress can fall back (e.g. a minority run) after unlocking
+			item.Progress = item.Target
+		}
+		if item.Progress > item.Target {
+			item.Progress = item.Target
+		}
+
+		resp.Achievements = append(resp.Achievements, item)
+	}
+	resp.Total = len(resp.Achievements)
+	return resp, nil
+}
+
+// AdminList returns every achievement definition, including inactive ones.
+func (s *AchievementService) AdminList() ([]models.Achievement, error) {
+	var achievements []models.Achievement
+	if err := s.db.Order("sort_order, id").Find(&achievements).Error; err != nil {
+		return nil, err
+	}
+	return achievements, nil
+}
+
+// Upsert creates or replaces an achievement definition. Users who already
+// meet a new rule unlock it on their next matching event.
+func (s *AchievementService) Upsert(id string, req *dto.UpsertAchievementRequest) (*models.Achievement, error) {
+	name := strings.TrimSpace(req.Name)
+	if !achievementIDPattern.MatchString(id) || name == "" {
+		return nil, ErrInvalidAchievement
+	}
+
+	rule := models.AchievementRule{
+		Type:      req.Rule.Type,
+		Threshold: req.Rule.Threshold,
+		Event:     req.Rule.Event,
+		Category:  req.Rule.Category,
+		DailyOnly: req.Rule.DailyOnly,
+	}
+	if err := validateAchievementRule(&rule); err != nil {
+		return nil, err
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	achievement := models.Achievement{
+		ID:          id,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Icon:        strings.TrimSpace(req.Icon),
+		Rule:        rule,
+		Active:      active,
+		SortOrder:   req.SortOrder,
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "icon", "rule", "active", "sort_order", "updated_at"}),
+	}).Create(&achievement).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.First(&achievement, "id = ?", id).Error; err != nil {
+		return nil, ErrAchievementNotFound
+	}
+	return &achievement, nil
+}
+
+func (s *AchievementService) unlock(userID uuid.UUID, a *models.Achievement) {
+	res := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserAchievement{
+		UserID:        userID,
+		AchievementID: a.ID,
+		UnlockedAt:    time.Now(),
+	})
+	if res.Error != nil {
+		log.Printf("Failed to unlock achievement %s for user %s: %v", a.ID, userID, res.Error)
+		return
+	}
+	if res.RowsAffected == 0 {
+		return // A concurrent evaluation got there first
+	}
+
+	if err := s.notifier.Notify(userID, Notification{
+		Type:  "achievement_unlocked",
+		Title: "Achievement unlocked: " + a.Name,
+		Body:  a.Description,
+		Data:  map[string]string{"achievement_id": a.ID},
+	}); err != nil {
+		log.Printf("Failed to send achievement notification to user %s: %v", userID, err)
+	}
+}
+
+func validateAchievementRule(rule *models.AchievementRule) error {
+	if _, ok := achievementEvaluators[rule.Type]; !ok {
+		return ErrInvalidAchievementRule
+	}
+	if rule.Threshold < 0 || (rule.Threshold == 0 && rule.Type != "categories_voted") {
+		return ErrInvalidAchievementRule
+	}
+	if (rule.Type == "event_count") != (rule.Event != "") {
+		return ErrInvalidAchievementRule
+	}
+	if rule.Type == "event_count" && !countedEvents[rule.Event] {
+		return ErrInvalidAchievementRule
+	}
+	if (rule.Category != "" || rule.DailyOnly) && rule.Type != "vote_count" {
+		return ErrInvalidAchievementRule
+	}
+	// Stats keep daily votes as one total, not per category
+	if rule.Category != "" && rule.DailyOnly {
+		return ErrInvalidAchievementRule
+	}
+	return nil
+}
+
+func triggeredBy(eval achievementEvaluator, rule *models.AchievementRule, events []string) bool {
+	triggers := eval.triggers
+	if triggers == nil {
+		triggers = []string{rule.Event}
+	}
+	for _, t := range triggers {
+		for _, e := range events {
+			if t == e {
+				return true
+			}
+		}
+	}
+	return false
+}
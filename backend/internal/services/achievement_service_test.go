@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+)
+
+func TestValidateAchievementRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  models.AchievementRule
+		valid bool
+	}{
+		{"vote count", models.AchievementRule{Type: "vote_count", Threshold: 10}, true},
+		{"vote count in a category", models.AchievementRule{Type: "vote_count", Threshold: 10, Category: "food"}, true},
+		{"daily vote count", models.AchievementRule{Type: "vote_count", Threshold: 30, DailyOnly: true}, true},
+		{"every category", models.AchievementRule{Type: "categories_voted"}, true},
+		{"some categories", models.AchievementRule{Type: "categories_voted", Threshold: 3}, true},
+		{"streak", models.AchievementRule{Type: "streak", Threshold: 7}, true},
+		{"minority run", models.AchievementRule{Type: "minority_run", Threshold: 10}, true},
+		{"counted event", models.AchievementRule{Type: "event_count", Event: EventShareCreated, Threshold: 1}, true},
+
+		{"unknown type", models.AchievementRule{Type: "likes", Threshold: 1}, false},
+		{"zero threshold", models.AchievementRule{Type: "vote_count"}, false},
+		{"negative threshold", models.AchievementRule{Type: "categories_voted", Threshold: -1}, false},
+		{"event count without event", models.AchievementRule{Type: "event_count", Threshold: 1}, false},
+		{"event on another type", models.AchievementRule{Type: "vote_count", Threshold: 1, Event: EventShareCreated}, false},
+		{"uncounted event", models.AchievementRule{Type: "event_count", Event: EventVoteCast, Threshold: 1}, false},
+		{"category on another type", models.AchievementRule{Type: "streak", Threshold: 7, Category: "food"}, false},
+		{"daily only on another type", models.AchievementRule{Type: "minority_run", Threshold: 7, DailyOnly: true}, false},
+		{"daily votes in a category", models.AchievementRule{Type: "vote_count", Threshold: 7, Category: "food", DailyOnly: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAchievementRule(&tt.rule)
+			if tt.valid && err != nil {
+				t.Errorf("validateAchievementRule = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidAchievementRule) {
+				t.Errorf("validateAchievementRule = %v, want ErrInvalidAchievementRule", err)
+			}
+		})
+	}
+}
+
+func TestAchievementEvaluators(t *testing.T) {
+	streak := 12
+	progress := &achievementProgress{
+		s: &AchievementService{subscriptions: NewSubscriptionService(nil, &config.Config{})},
+		stats: &models.UserStats{
+			TotalVotes:     40,
+			CategoryCounts: map[string]int{"life": 25, "deep": 8, "general": 5, "imported": 2},
+			DailyVotes:     8,
+			MinorityRun:    3,
+		},
+		streak:   &streak,
+		counters: map[string]int{EventShareCreated: 2},
+	}
+
+	tests := []struct {
+		name          string
+		rule          models.AchievementRule
+		value, target int
+	}{
+		{"all votes", models.AchievementRule{Type: "vote_count", Threshold: 100}, 40, 100},
+		{"votes in a category", models.AchievementRule{Type: "vote_count", Threshold: 20, Category: "life"}, 25, 20},
+		{"votes in an unvoted category", models.AchievementRule{Type: "vote_count", Threshold: 1, Category: "sports"}, 0, 1},
+		{"daily votes", models.AchievementRule{Type: "vote_count", Threshold: 30, DailyOnly: true}, 8, 30},
+		{"every category", models.AchievementRule{Type: "categories_voted"}, 2, 6},
+		{"some categories", models.AchievementRule{Type: "categories_voted", Threshold: 2}, 4, 2},
+		{"streak", models.AchievementRule{Type: "streak", Threshold: 7}, 12, 7},
+		{"minority run", models.AchievementRule{Type: "minority_run", Threshold: 10}, 3, 10},
+		{"counted event", models.AchievementRule{Type: "event_count", Event: EventShareCreated, Threshold: 10}, 2, 10},
+		{"event never counted", models.AchievementRule{Type: "event_count", Event: EventLobbyHosted, Threshold: 5}, 0, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, target, err := achievementEvaluators[tt.rule.Type].value(progress, &tt.rule)
+			if err != nil {
+				t.Fatalf("value: %v", err)
+			}
+			if value != tt.value || target != tt.target {
+				t.Errorf("value, target = %d, %d; want %d, %d", value, target, tt.value, tt.target)
+			}
+		})
+	}
+}
+
+func TestPlayableCategories(t *testing.T) {
+	all := []string{"deep", "funny", "life", "love", "superpower", "tech"}
+	if got := playableCategories(nil); !reflect.DeepEqual(got, all) {
+		t.Errorf("playableCategories(nil) = %v, want %v", got, all)
+	}
+	want := []string{"funny", "life", "love", "superpower", "tech"}
+	if got := playableCategories([]string{"deep", "spicy"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("playableCategories(deep, spicy) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	appleIssuer  = "https://appleid.apple.com"
+	appleKeysURL = "https://appleid.apple.com/auth/keys"
+	// appleKeysTTL is how long fetched signing keys are trusted before a
+	// refetch; Apple rotates them rarely.
+	appleKeysTTL = 24 * time.Hour
+	// appleKeysMinRefresh throttles refetches triggered by unknown key IDs,
+	// so garbage tokens can't make us hammer Apple.
+	appleKeysMinRefresh = time.Minute
+)
+
+var ErrAppleTokenInvalid = errors.New("invalid Apple identity token")
+
+// AppleIdentityClaims are the identity token claims sign-in relies on.
+type AppleIdentityClaims struct {
+	jwt.RegisteredClaims
+	Email         string    `json:"email"`
+	EmailVerified appleBool `json:"email_verified"`
+}
+
+// appleBool accepts both true and "true"; Apple has sent either form.
+type appleBool bool
+
+func (b *appleBool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case `true`, `"true"`:
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// AppleKeySet caches the public keys Apple signs identity tokens with.
+type AppleKeySet struct {
+	client      *http.Client
+	url         string
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func NewAppleKeySet() *AppleKeySet {
+	return &AppleKeySet{client: &http.Client{Timeout: 10 * time.Second}, url: appleKeysURL}
+}
+
+// Verify checks an identity token's signature against Apple's keys and its
+// issuer, audience (the app's bundle ID) and expiry.
+func (k *AppleKeySet) Verify(raw, audience string) (*AppleIdentityClaims, error) {
+	if audience == "" {
+		return nil, errors.New("Sign in with Apple is not configured")
+	}
+
+	claims := &AppleIdentityClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return k.key(kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(appleIssuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAppleTokenInvalid, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrAppleTokenInvalid)
+	}
+	return claims, nil
+}
+
+// key returns the signing key with the given ID, refetching the key set
+// when it is stale or doesn't contain kid. A stale key stays usable while
+// Apple can't be reached.
+func (k *AppleKeySet) key(kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	key, ok := k.keys[kid]
+	if ok && time.Since(k.fetchedAt) <= appleKeysTTL {
+		return key, nil
+	}
+	if time.Since(k.attemptedAt) > appleKeysMinRefresh {
+		k.attemptedAt = time.Now()
+		if err := k.fetch(); err != nil {
+			if ok {
+				return key, nil
+			}
+			return nil, err
+		}
+		key, ok = k.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown Apple signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (k *AppleKeySet) fetch() error {
+	resp, err := k.client.Get(k.url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch Apple keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch Apple keys: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode Apple keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return errors.New("Apple key set contains no RSA keys")
+	}
+
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	return nil
+}
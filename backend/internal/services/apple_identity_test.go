@@ -0,0 +1,136 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testBundleID = "app.wouldyou.test"
+
+func testAppleKeySet(t *testing.T, keys map[string]*rsa.PrivateKey) *AppleKeySet {
+	t.Helper()
+	type jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(srv.Close)
+
+	keySet := NewAppleKeySet()
+	keySet.url = srv.URL
+	return keySet
+}
+
+func testAppleToken(t *testing.T, key *rsa.PrivateKey, kid string, edit func(jwt.MapClaims)) string {
+	t.Helper()
+	claims := jwt.MapClaims{
+		"iss":            appleIssuer,
+		"aud":            testBundleID,
+		"sub":            "001234.abcdef",
+		"email":          "someone@example.com",
+		"email_verified": "true",
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(10 * time.Minute).Unix(),
+	}
+	if edit != nil {
+		edit(claims)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+func TestAppleKeySetVerify(t *testing.T) {
+	apple, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forger, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keySet := testAppleKeySet(t, map[string]*rsa.PrivateKey{"k1": apple})
+
+	claims, err := keySet.Verify(testAppleToken(t, apple, "k1", nil), testBundleID)
+	if err != nil {
+		t.Fatalf("Verify of a valid token: %v", err)
+	}
+	if claims.Subject != "001234.abcdef" || claims.Email != "someone@example.com" || !claims.EmailVerified {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"signed by another key", testAppleToken(t, forger, "k1", nil)},
+		{"unknown key ID", testAppleToken(t, apple, "k2", nil)},
+		{"wrong audience", testAppleToken(t, apple, "k1", func(c jwt.MapClaims) { c["aud"] = "com.other.app" })},
+		{"wrong issuer", testAppleToken(t, apple, "k1", func(c jwt.MapClaims) { c["iss"] = "https://evil.example" })},
+		{"expired", testAppleToken(t, apple, "k1", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() })},
+		{"no expiry", testAppleToken(t, apple, "k1", func(c jwt.MapClaims) { delete(c, "exp") })},
+		{"no subject", testAppleToken(t, apple, "k1", func(c jwt.MapClaims) { delete(c, "sub") })},
+		{"unsigned payload", func() string {
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+				"iss": appleIssuer, "aud": testBundleID, "sub": "x", "exp": time.Now().Add(time.Hour).Unix(),
+			}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return token
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := keySet.Verify(tt.token, testBundleID); !errors.Is(err, ErrAppleTokenInvalid) {
+				t.Errorf("Verify error = %v, want ErrAppleTokenInvalid", err)
+			}
+		})
+	}
+}
+
+func TestAppleEmailVerifiedForms(t *testing.T) {
+	tests := map[string]bool{
+		`{"email_verified":true}`:    true,
+		`{"email_verified":"true"}`:  true,
+		`{"email_verified":false}`:   false,
+		`{"email_verified":"false"}`: false,
+		`{}`:                         false,
+	}
+	for input, want := range tests {
+		var claims AppleIdentityClaims
+		if err := json.Unmarshal([]byte(input), &claims); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", input, err)
+		}
+		if bool(claims.EmailVerified) != want {
+			t.Errorf("Unmarshal(%s).EmailVerified = %v, want %v", input, claims.EmailVerified, want)
+		}
+	}
+}
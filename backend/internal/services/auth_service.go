@@ -4,9 +4,10 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
-	"encoding/json"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
@@ -24,24 +26,32 @@ var (
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrInvalidToken       = errors.New("invalid or expired refresh token")
 	ErrUserNotFound       = errors.New("user not found")
+	ErrWeakPassword       = errors.New("password must be at least 8 characters")
 )
 
 type AuthService struct {
-	db  *gorm.DB
-	cfg *config.Config
+	db               *gorm.DB
+	cfg              *config.Config
+	emailService     *EmailService
+	challengeService *ChallengeService
+	appleKeys        *AppleKeySet
 }
 
-func NewAuthService(db *gorm.DB, cfg *config.Config) *AuthService {
-	return &AuthService{db: db, cfg: cfg}
+func NewAuthService(db *gorm.DB, cfg *config.Config, emailService *EmailService, challengeService *ChallengeService) *AuthService {
+	return &AuthService{db: db, cfg: cfg, emailService: emailService, challengeService: challengeService, appleKeys: NewAppleKeySet()}
 }
 
 func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, error) {
 	if len(req.Email) == 0 || len(req.Password) < 8 {
 		return nil, errors.New("email required and password must be at least 8 characters")
 	}
+	email, err := NormalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
 
 	var existing models.User
-	if err := s.db.Where("email = ?", req.Email).First(&existing).Error; err == nil {
+	if err := s.db.Where("email = ?", email).First(&existing).Error; err == nil {
 		return nil, ErrEmailTaken
 	}
 
@@ -52,15 +62,31 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, err
 
 	user := models.User{
 		ID:       uuid.New(),
-		Email:    req.Email,
+		Email:    email,
 		Password: string(hash),
+		Locale:   NormalizeLocale(req.Locale),
 	}
 
 	if err := s.db.Create(&user).Error; err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
+	s.recordShareInstall(&user, req.ShareCode)
 
-	return s.generateTokenPair(&user)
+	// Don't hold up sign-up on the mail server
+	go func() {
+		if err := s.emailService.SendVerification(&user); err != nil {
+			log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+		}
+	}()
+
+	merged := s.mergeGuest(&user, req.GuestToken, req.GuestID)
+
+	resp, err := s.generateTokenPair(&user)
+	if err != nil {
+		return nil, err
+	}
+	resp.MergedGuestVotes = merged
+	return resp, nil
 }
 
 func (s *AuthService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
@@ -73,7 +99,16 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
 		return nil, ErrInvalidCredentials
 	}
 
-	return s.generateTokenPair(&user)
+	restored := s.restoreIfPendingDeletion(&user)
+	merged := s.mergeGuest(&user, req.GuestToken, req.GuestID)
+
+	resp, err := s.generateTokenPair(&user)
+	if err != nil {
+		return nil, err
+	}
+	resp.AccountRestored = restored
+	resp.MergedGuestVotes = merged
+	return resp, nil
 }
 
 func (s *AuthService) Refresh(req *dto.RefreshRequest) (*dto.AuthResponse, error) {
@@ -107,83 +142,129 @@ func (s *AuthService) Logout(req *dto.LogoutRequest) error {
 		Update("revoked", true).Error
 }
 
+// RequestPasswordReset starts a password reset. The lookup and email run in
+// the background so the response time doesn't reveal whether the address is
+// registered.
+func (s *AuthService) RequestPasswordReset(req *dto.ForgotPasswordRequest) {
+	go s.emailService.SendPasswordReset(req.Email)
+}
+
+// ResetPassword sets a new password from a reset token and revokes every
+// refresh token, signing the user out on all devices.
+func (s *AuthService) ResetPassword(req *dto.ResetPasswordRequest) error {
+	if len(req.NewPassword) < 8 {
+		return ErrWeakPassword
+	}
+
+	user, err := s.emailService.ConsumePasswordReset(req.Token)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("password", string(hash)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked = false", user.ID).
+			Update("revoked", true).Error
+	})
+}
+
 // DeleteAccount implements Apple Guideline 5.1.1(v) - account deletion.
-// Scrubs all user data: tokens, subscriptions, reports, blocks, then soft-deletes user.
-func (s *AuthService) DeleteAccount(userID uuid.UUID, password string) error {
+// The account is scheduled for purge after the grace period and all
+// sessions are revoked; logging in again before then restores it. The
+// purge itself (DeletionService) removes the data.
+func (s *AuthService) DeleteAccount(userID uuid.UUID, password string) (time.Time, error) {
 	var user models.User
 	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
-		return ErrUserNotFound
+		return time.Time{}, ErrUserNotFound
 	}
 
 	// Verify password (skip for Apple Sign-In users who have no password)
 	if user.Password != "" && password != "" {
 		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-			return ErrInvalidCredentials
+			return time.Time{}, ErrInvalidCredentials
 		}
 	}
 
-	// Scrub all associated data in a transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	// Repeating the request keeps the original schedule
+	if user.PurgeAfter != nil {
+		return *user.PurgeAfter, nil
+	}
+
+	now := time.Now()
+	purgeAfter := now.Add(s.cfg.AccountDeletionGrace)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Revoke all refresh tokens
-		tx.Where("user_id = ?", userID).Delete(&models.RefreshToken{})
+		if err := tx.Model(&models.RefreshToken{}).Where("user_id = ?", userID).
+			Update("revoked", true).Error; err != nil {
+			return err
+		}
 
-		// Remove subscriptions
-		tx.Where("user_id = ?", userID).Delete(&models.Subscription{})
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"deletion_requested_at": now,
+			"purge_after":           purgeAfter,
+		}).Error
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
 
-		// Remove reports filed by user
-		tx.Where("reporter_id = ?", userID).Delete(&models.Report{})
+	return purgeAfter, nil
+}
 
-		// Remove blocks
-		tx.Where("blocker_id = ? OR blocked_id = ?", userID, userID).Delete(&models.Block{})
+// restoreIfPendingDeletion cancels a scheduled deletion when the user signs
+// in during the grace period. It reports whether the account was restored.
+func (s *AuthService) restoreIfPendingDeletion(user *models.User) bool {
+	if user.PurgeAfter == nil {
+		return false
+	}
 
-		// Soft-delete the user (GORM DeletedAt)
-		return tx.Delete(&user).Error
-	})
+	if err := s.db.Model(user).Updates(map[string]interface{}{
+		"deletion_requested_at": nil,
+		"purge_after":           nil,
+	}).Error; err != nil {
+		return false
+	}
+	user.DeletionRequestedAt = nil
+	user.PurgeAfter = nil
+	return true
 }
 
 // AppleSignIn handles Sign in with Apple (Guideline 4.8).
 // Verifies Apple identity token and creates/finds a user.
 func (s *AuthService) AppleSignIn(req *dto.AppleSignInRequest) (*dto.AuthResponse, error) {
-	// Decode the Apple identity token to extract the subject (Apple user ID)
-	// In production, verify the token signature against Apple's public keys at:
-	// https://appleid.apple.com/auth/keys
-	parts := strings.Split(req.IdentityToken, ".")
-	if len(parts) != 3 {
-		return nil, errors.New("invalid Apple identity token format")
-	}
-
-	// Decode the payload (base64url)
-	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	claims, err := s.appleKeys.Verify(req.IdentityToken, s.cfg.AppleBundleID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode Apple token payload: %w", err)
-	}
-
-	var claims struct {
-		Sub   string `json:"sub"`
-		Email string `json:"email"`
-	}
-	if err := json.Unmarshal(payload, &claims); err != nil {
-		return nil, fmt.Errorf("failed to parse Apple token claims: %w", err)
-	}
-
-	if claims.Sub == "" {
-		return nil, errors.New("Apple token missing subject")
+		return nil, err
 	}
 
-	// Use email from token, or from the request (first sign-in only)
+	// Only the token's email is vouched for by Apple; the request's is a
+	// client hint sent on first sign-in and never matches other accounts
+	relayEmail := claims.Subject + "@privaterelay.appleid.com"
 	email := claims.Email
 	if email == "" {
 		email = req.Email
 	}
 	if email == "" {
-		email = claims.Sub + "@privaterelay.appleid.com"
+		email = relayEmail
 	}
 
 	// Find existing user by Apple ID (stored in email field with apple: prefix)
-	// or by email match
+	// or by an email Apple verified
 	var user models.User
-	appleEmail := "apple:" + claims.Sub
-	err = s.db.Where("email = ? OR email = ?", appleEmail, email).First(&user).Error
+	appleEmail := "apple:" + claims.Subject
+	matches := []string{appleEmail, relayEmail}
+	if claims.Email != "" && claims.EmailVerified {
+		matches = append(matches, claims.Email)
+	}
+	err = s.db.Where("email IN ?", matches).First(&user).Error
 
 	if err != nil {
 		// Create new user for first-time Apple sign-in
@@ -192,12 +273,144 @@ func (s *AuthService) AppleSignIn(req *dto.AppleSignInRequest) (*dto.AuthRespons
 			Email:    email,
 			Password: "", // Apple users have no password
 		}
+		if claims.Email != "" && claims.EmailVerified {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+		}
 		if err := s.db.Create(&user).Error; err != nil {
 			return nil, fmt.Errorf("failed to create Apple user: %w", err)
 		}
+		s.recordShareInstall(&user, req.ShareCode)
 	}
 
-	return s.generateTokenPair(&user)
+	restored := s.restoreIfPendingDeletion(&user)
+	merged := s.mergeGuest(&user, req.GuestToken, req.GuestID)
+
+	resp, err := s.generateTokenPair(&user)
+	if err != nil {
+		return nil, err
+	}
+	resp.AccountRestored = restored
+	resp.MergedGuestVotes = merged
+	return resp, nil
+}
+
+// IssueGuestToken hands out a signed guest identity. An existing token is
+// renewed with the same ID; a legacy device ID is adopted while raw IDs are
+// allowed; otherwise a new ID is created, limited per client IP
+// (GUEST_TOKENS_PER_IP) so guests can't reset the daily vote quota by
+// minting identities.
+func (s *AuthService) IssueGuestToken(req *dto.GuestTokenRequest, clientIP string) (*dto.GuestTokenResponse, error) {
+	var guestID string
+
+	switch {
+	case req.GuestToken != "":
+		id, err := ParseGuestToken(s.cfg, req.GuestToken, true)
+		if err != nil {
+			return nil, err
+		}
+		var count int64
+		s.db.Model(&models.GuestIdentity{}).Where("id = ?", id).Count(&count)
+		if count == 0 {
+			return nil, ErrInvalidGuestToken
+		}
+		guestID = id
+
+	case req.DeviceID != "" && s.cfg.AllowRawGuestIDs:
+		deviceID := strings.TrimSpace(req.DeviceID)
+		if !IsRawGuestID(deviceID) {
+			return nil, ErrInvalidGuestToken
+		}
+		identity := models.GuestIdentity{ID: deviceID, IPHash: hashToken(clientIP), Legacy: true}
+		if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&identity).Error; err != nil {
+			return nil, err
+		}
+		// An ID that is already a signed guest's can't be adopted by
+		// whoever knows it
+		if err := s.db.First(&identity, "id = ?", deviceID).Error; err != nil {
+			return nil, err
+		}
+		if !identity.Legacy {
+			return nil, ErrInvalidGuestToken
+		}
+		guestID = deviceID
+
+	default:
+		// Generous by default: carrier NAT puts many phones behind one IP
+		if s.cfg.GuestTokensPerIP > 0 {
+			var recent int64
+			s.db.Model(&models.GuestIdentity{}).
+				Where("ip_hash = ? AND created_at > ?", hashToken(clientIP), time.Now().Add(-24*time.Hour)).
+				Count(&recent)
+			if recent >= int64(s.cfg.GuestTokensPerIP) {
+				return nil, ErrTooManyGuests
+			}
+		}
+
+		raw := make([]byte, 16)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+		}
+		identity := models.GuestIdentity{ID: guestIDPrefix + hex.EncodeToString(raw), IPHash: hashToken(clientIP)}
+		if err := s.db.Create(&identity).Error; err != nil {
+			return nil, err
+		}
+		guestID = identity.ID
+	}
+
+	token, expires, err := NewGuestToken(s.cfg, guestID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.GuestTokenResponse{GuestToken: token, GuestID: guestID, ExpiresAt: expires}, nil
+}
+
+// mergeGuest moves the votes cast under the device's guest identity to the
+// user. The identity comes from a signed guest token, or from a raw guest ID
+// while those are still accepted. Failures are logged rather than failing
+// the sign-in.
+func (s *AuthService) mergeGuest(user *models.User, guestToken, rawGuestID string) int {
+	guestID := ""
+	if guestToken != "" {
+		// Expired tokens still prove the device owned the identity
+		guestID, _ = ParseGuestToken(s.cfg, guestToken, true)
+	} else if s.cfg.AllowRawGuestIDs && IsRawGuestID(strings.TrimSpace(rawGuestID)) {
+		guestID = strings.TrimSpace(rawGuestID)
+	}
+	if guestID == "" {
+		return 0
+	}
+
+	// The guest's devices now belong to the account
+	if err := s.db.Model(&models.DeviceToken{}).Where("guest_id = ? AND user_id = ?", guestID, uuid.Nil).
+		Updates(map[string]interface{}{"user_id": user.ID, "guest_id": ""}).Error; err != nil {
+		log.Printf("Failed to move guest devices to user %s: %v", user.ID, err)
+	}
+
+	merged, err := s.challengeService.MergeGuestVotes(user.ID, guestID)
+	if err != nil {
+		log.Printf("Failed to merge guest votes into user %s: %v", user.ID, err)
+		return 0
+	}
+	return merged
+}
+
+// recordShareInstall attributes a new account to the share link that led
+// to it. Only new accounts are attributed; unknown codes are ignored.
+func (s *AuthService) recordShareInstall(user *models.User, code string) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return
+	}
+
+	var share models.Share
+	if err := s.db.Select("id").Where("code = ?", code).First(&share).Error; err != nil {
+		return
+	}
+	install := models.ShareInstall{ShareID: share.ID, UserID: user.ID, CreatedAt: user.CreatedAt}
+	if err := s.db.Create(&install).Error; err != nil {
+		log.Printf("Failed to attribute user %s to share %s: %v", user.ID, code, err)
+	}
 }
 
 func (s *AuthService) generateTokenPair(user *models.User) (*dto.AuthResponse, error) {
@@ -215,8 +428,9 @@ func (s *AuthService) generateTokenPair(user *models.User) (*dto.AuthResponse, e
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		User: dto.UserResponse{
-			ID:    user.ID,
-			Email: user.Email,
+			ID:            user.ID,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerifiedAt != nil,
 		},
 	}, nil
 }
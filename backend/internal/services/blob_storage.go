@@ -0,0 +1,105 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+)
+
+// BlobStorage stores uploaded files (avatars, exports) under opaque keys
+// such as "avatars/<user>/<id>.png". Swap the implementation to move media
+// to S3 or another object store.
+type BlobStorage interface {
+	Put(key string, r io.Reader, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	// URL returns the public URL of a key.
+	URL(key string) string
+}
+
+// LocalBlobStorage keeps blobs on local disk under MEDIA_DIR. The server
+// exposes the directory at /media.
+type LocalBlobStorage struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalBlobStorage(cfg *config.Config) (*LocalBlobStorage, error) {
+	if err := os.MkdirAll(cfg.MediaDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create media directory: %w", err)
+	}
+
+	baseURL := cfg.MediaBaseURL
+	if baseURL == "" {
+		baseURL = cfg.PublicBaseURL + "/media"
+	}
+	return &LocalBlobStorage{dir: cfg.MediaDir, baseURL: baseURL}, nil
+}
+
+// Dir is the directory served at /media.
+func (s *LocalBlobStorage) Dir() string {
+	return s.dir
+}
+
+func (s *LocalBlobStorage) Put(key string, r io.Reader, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temp file first so readers never see a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalBlobStorage) Get(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *LocalBlobStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalBlobStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path maps a key into the media directory, rejecting keys that would
+// escape it.
+func (s *LocalBlobStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if key == "" || strings.Contains(key, "..") || clean == "/" {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
@@ -6,18 +6,23 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ChallengeService struct {
 	db                *gorm.DB
 	questionGenerator *QuestionGeneratorService
+	subscriptions     *SubscriptionService
+	achievements      *AchievementService
+	progression       *ProgressionService
 }
 
-func NewChallengeService(db *gorm.DB, qg *QuestionGeneratorService) *ChallengeService {
-	return &ChallengeService{db: db, questionGenerator: qg}
+func NewChallengeService(db *gorm.DB, qg *QuestionGeneratorService, subs *SubscriptionService, achievements *AchievementService, progression *ProgressionService) *ChallengeService {
+	return &ChallengeService{db: db, questionGenerator: qg, subscriptions: subs, achievements: achievements, progression: progression}
 }
 
 // GetDailyChallenge returns today's challenge, creating one with rotation if needed
@@ -103,6 +108,20 @@ func (s *ChallengeService) GetChallengesByCategory(category string, userID uuid.
 		}
 	}
 
+	// The caller's votes on the whole page, in one query
+	userChoices := make(map[uuid.UUID]string)
+	if userID != uuid.Nil && len(challenges) > 0 {
+		ids := make([]uuid.UUID, len(challenges))
+		for i, ch := range challenges {
+			ids[i] = ch.ID
+		}
+		var votes []models.Vote
+		s.db.Where("user_id = ? AND challenge_id IN ?", userID, ids).Find(&votes)
+		for _, v := range votes {
+			userChoices[v.ChallengeID] = v.Choice
+		}
+	}
+
 	result := make([]map[string]interface{}, 0)
 	for _, ch := range challenges {
 		total := ch.VotesA + ch.VotesB
@@ -113,17 +132,9 @@ func (s *ChallengeService) GetChallengesByCategory(category string, userID uuid.
 			percentB = (ch.VotesB * 100) / total
 		}
 
-		userChoice := ""
-		if userID != uuid.Nil {
-			var vote models.Vote
-			if err := s.db.Where("user_id = ? AND challenge_id = ?", userID, ch.ID).First(&vote).Error; err == nil {
-				userChoice = vote.Choice
-			}
-		}
-
 		result = append(result, map[string]interface{}{
 			"challenge":   ch,
-			"user_choice": userChoice,
+			"user_choice": userChoices[ch.ID],
 			"percent_a":   percentA,
 			"percent_b":   percentB,
 			"total_votes": total,
@@ -154,17 +165,26 @@ func (s *ChallengeService) ensureCategoryChallenges(category string) {
 	}
 }
 
-// GetRandomChallenge returns a random non-daily challenge the user hasn't voted on
-func (s *ChallengeService) GetRandomChallenge(userID uuid.UUID) (*models.Challenge, error) {
+// GetRandomChallenge returns a random non-daily challenge the user hasn't voted on.
+// Challenges in excludeCategories (e.g. locked premium categories) are never returned.
+func (s *ChallengeService) GetRandomChallenge(userID uuid.UUID, excludeCategories []string) (*models.Challenge, error) {
 	// Ensure some non-daily challenges exist
 	for _, cat := range []string{"life", "deep", "superpower", "funny", "love", "tech"} {
 		s.ensureCategoryChallenges(cat)
 	}
 
+	candidates := func() *gorm.DB {
+		query := s.db.Where("is_daily = ?", false)
+		if len(excludeCategories) > 0 {
+			query = query.Where("category NOT IN ?", excludeCategories)
+		}
+		return query
+	}
+
 	var challenge models.Challenge
 	subQuery := s.db.Model(&models.Vote{}).Select("challenge_id").Where("user_id = ?", userID)
 
-	err := s.db.Where("is_daily = ? AND id NOT IN (?)", false, subQuery).
+	err := candidates().Where("id NOT IN (?)", subQuery).
 		Order("RANDOM()").
 		First(&challenge).Error
 
@@ -174,7 +194,7 @@ func (s *ChallengeService) GetRandomChallenge(userID uuid.UUID) (*models.Challen
 
 	// Check total non-daily challenge count
 	var totalCount int64
-	s.db.Model(&models.Challenge{}).Where("is_daily = ?", false).Count(&totalCount)
+	candidates().Model(&models.Challenge{}).Count(&totalCount)
 
 	if totalCount == 0 {
 		// No challenges at all - try to generate some
@@ -186,7 +206,7 @@ func (s *ChallengeService) GetRandomChallenge(userID uuid.UUID) (*models.Challen
 				return nil, errors.New("no challenges available and generation failed")
 			}
 			// Try again after generation
-			err = s.db.Where("is_daily = ? AND id NOT IN (?)", false, subQuery).
+			err = candidates().Where("id NOT IN (?)", subQuery).
 				Order("RANDOM()").
 				First(&challenge).Error
 			if err == nil {
@@ -197,7 +217,7 @@ func (s *ChallengeService) GetRandomChallenge(userID uuid.UUID) (*models.Challen
 	}
 
 	// User has voted on all challenges - return any random one
-	err = s.db.Where("is_daily = ?", false).Order("RANDOM()").First(&challenge).Error
+	err = candidates().Order("RANDOM()").First(&challenge).Error
 	if err != nil {
 		return nil, errors.New("no challenges available")
 	}
@@ -205,10 +225,16 @@ func (s *ChallengeService) GetRandomChallenge(userID uuid.UUID) (*models.Challen
 	return &challenge, nil
 }
 
+var (
+	ErrInvalidChoice     = errors.New("invalid choice, must be A or B")
+	ErrAlreadyVoted      = errors.New("already voted on this challenge")
+	ErrChallengeNotFound = errors.New("challenge not found")
+)
+
 // Vote records a user's or guest's vote
 func (s *ChallengeService) Vote(userID uuid.UUID, guestID string, challengeID uuid.UUID, choice string) (*models.Vote, error) {
-	if choice != "A" && choice != "B" {
-		return nil, errors.New("invalid choice, must be A or B")
+	if err := s.validateVote(userID, challengeID, choice); err != nil {
+		return nil, err
 	}
 
 	// Check guest daily limit
@@ -221,15 +247,9 @@ func (s *ChallengeService) Vote(userID uuid.UUID, guestID string, challengeID uu
 		// Check if guest already voted on this challenge
 		var existing models.Vote
 		if err := s.db.Where("guest_id = ? AND challenge_id = ?", guestID, challengeID).First(&existing).Error; err == nil {
-			return nil, errors.New("already voted on this challenge")
+			return nil, ErrAlreadyVoted
 		}
-	} else if userID != uuid.Nil {
-		// Check if authenticated user already voted
-		var existing models.Vote
-		if err := s.db.Where("user_id = ? AND challenge_id = ?", userID, challengeID).First(&existing).Error; err == nil {
-			return nil, errors.New("already voted on this challenge")
-		}
-	} else {
+	} else if userID == uuid.Nil {
 		return nil, errors.New("authentication required")
 	}
 
@@ -240,25 +260,305 @@ func (s *ChallengeService) Vote(userID uuid.UUID, guestID string, challengeID uu
 		Choice:      choice,
 	}
 
-	if err := s.db.Create(vote).Error; err != nil {
+	inserted, err := s.recordVote(vote)
+	if err != nil {
 		return nil, err
 	}
-
-	// Update vote counts
-	if choice == "A" {
-		s.db.Model(&models.Challenge{}).Where("id = ?", challengeID).Update("votes_a", gorm.Expr("votes_a + 1"))
-	} else {
-		s.db.Model(&models.Challenge{}).Where("id = ?", challengeID).Update("votes_b", gorm.Expr("votes_b + 1"))
+	if !inserted {
+		return nil, ErrAlreadyVoted
 	}
 
 	// Update streak for authenticated users
 	if userID != uuid.Nil {
+		markStatsDirty(s.db, userID, false)
 		s.updateStreak(userID)
+		go s.achievements.Record(userID, EventVoteCast, EventStreakUpdated)
+		go s.progression.AwardVote(userID, challengeID)
 	}
 
 	return vote, nil
 }
 
+// validateVote applies the rules every vote must pass, online or synced.
+func (s *ChallengeService) validateVote(userID, challengeID uuid.UUID, choice string) error {
+	if choice != "A" && choice != "B" {
+		return ErrInvalidChoice
+	}
+
+	var challenge models.Challenge
+	if err := s.db.Select("id", "category", "is_daily").First(&challenge, "id = ?", challengeID).Error; err != nil {
+		return ErrChallengeNotFound
+	}
+
+	if entitlement := s.voteEntitlement(&challenge); entitlement != "" {
+		return s.subscriptions.RequireEntitlement(userID, entitlement)
+	}
+	return nil
+}
+
+// voteEntitlement is the entitlement needed to answer a challenge. Locked
+// categories need theirs, the same rule GET /challenges/category/:category
+// enforces, except for the daily: it is drawn from every category and is
+// everyone's question of the day.
+func (s *ChallengeService) voteEntitlement(challenge *models.Challenge) string {
+	if challenge.IsDaily {
+		return ""
+	}
+	return s.subscriptions.CategoryEntitlement(challenge.Category)
+}
+
+// syncVoteTime checks the client-reported time of an offline vote against
+// now. It returns the time to store, clamped to now within the allowed clock
+// skew, or the reason the vote is rejected.
+func syncVoteTime(votedAt, now time.Time) (time.Time, string) {
+	switch {
+	case votedAt.IsZero():
+		return time.Time{}, "voted_at is required"
+	case votedAt.After(now.Add(clockSkewAllowance)):
+		return time.Time{}, "voted_at is in the future"
+	case votedAt.Before(now.Add(-offlineVoteWindow)):
+		return time.Time{}, "voted_at is outside the sync window"
+	case votedAt.After(now):
+		return now, ""
+	}
+	return votedAt, ""
+}
+
+// recordVote inserts the vote and bumps the challenge counter atomically.
+// It returns false without touching counters if the user already voted on
+// the challenge (enforced by idx_votes_user_challenge).
+func (s *ChallengeService) recordVote(vote *models.Vote) (bool, error) {
+	inserted := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(vote)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		inserted = true
+
+		column := "votes_a"
+		if vote.Choice == "B" {
+			column = "votes_b"
+		}
+		return tx.Model(&models.Challenge{}).Where("id = ?", vote.ChallengeID).
+			Update(column, gorm.Expr(column+" + 1")).Error
+	})
+	return inserted, err
+}
+
+const (
+	// MaxSyncBatch caps how many offline votes one sync request may carry
+	MaxSyncBatch = 200
+	// offlineVoteWindow is how far back an offline vote may be dated
+	offlineVoteWindow = 30 * 24 * time.Hour
+	// clockSkewAllowance tolerates client clocks running slightly ahead
+	clockSkewAllowance = 5 * time.Minute
+)
+
+// SyncVotes applies votes cast offline. Each vote goes through the same
+// rules as Vote and is inserted idempotently per user and challenge, so
+// retrying a sync is safe. Streaks are rebuilt from the stored vote dates
+// afterwards, which now include the client's answer dates.
+func (s *ChallengeService) SyncVotes(userID uuid.UUID, items []dto.SyncVoteItem) *dto.SyncVotesResponse {
+	now := time.Now()
+	resp := &dto.SyncVotesResponse{Results: make([]dto.SyncVoteResult, 0, len(items))}
+	seen := make(map[uuid.UUID]bool, len(items))
+	var accepted []uuid.UUID
+
+	reject := func(challengeID, reason string) {
+		resp.Results = append(resp.Results, dto.SyncVoteResult{ChallengeID: challengeID, Status: "rejected", Reason: reason})
+		resp.Rejected++
+	}
+	duplicate := func(challengeID string) {
+		resp.Results = append(resp.Results, dto.SyncVoteResult{ChallengeID: challengeID, Status: "duplicate"})
+		resp.Duplicates++
+	}
+
+	for _, item := range items {
+		challengeID, err := uuid.Parse(item.ChallengeID)
+		if err != nil {
+			reject(item.ChallengeID, "invalid challenge ID")
+			continue
+		}
+		if seen[challengeID] {
+			duplicate(item.ChallengeID)
+			continue
+		}
+		seen[challengeID] = true
+
+		if err := s.validateVote(userID, challengeID, item.Choice); err != nil {
+			reject(item.ChallengeID, err.Error())
+			continue
+		}
+
+		votedAt, reason := syncVoteTime(item.VotedAt, now)
+		if reason != "" {
+			reject(item.ChallengeID, reason)
+			continue
+		}
+
+		vote := &models.Vote{
+			UserID:      userID,
+			ChallengeID: challengeID,
+			Choice:      item.Choice,
+			CreatedAt:   votedAt,
+		}
+		inserted, err := s.recordVote(vote)
+		if err != nil {
+			log.Printf("Offline sync failed for user %s challenge %s: %v", userID, challengeID, err)
+			reject(item.ChallengeID, "failed to record vote")
+			continue
+		}
+		if !inserted {
+			duplicate(item.ChallengeID)
+			continue
+		}
+
+		resp.Results = append(resp.Results, dto.SyncVoteResult{ChallengeID: item.ChallengeID, Status: "accepted"})
+		resp.Accepted++
+		accepted = append(accepted, challengeID)
+	}
+
+	if resp.Accepted > 0 {
+		markStatsDirty(s.db, userID, true)
+		s.rebuildStreak(userID)
+		go s.achievements.Record(userID, EventVoteCast, EventStreakUpdated)
+		go func() {
+			for _, id := range accepted {
+				s.progression.AwardVote(userID, id)
+			}
+		}()
+	}
+
+	return resp
+}
+
+// rebuildStreak recomputes a user's streak from the days they have votes on.
+// Unlike updateStreak it does not assume votes arrive in date order, so it
+// is used after offline votes are backfilled.
+func (s *ChallengeService) rebuildStreak(userID uuid.UUID) {
+	var days []time.Time
+	s.db.Model(&models.Vote{}).
+		Where("user_id = ?", userID).
+		Distinct("DATE(created_at)").
+		Order("DATE(created_at) ASC").
+		Pluck("DATE(created_at)", &days)
+	if len(days) == 0 {
+		return
+	}
+
+	var totalVotes int64
+	s.db.Model(&models.Vote{}).Where("user_id = ?", userID).Count(&totalVotes)
+
+	longest, run := 1, 1
+	for i := 1; i < len(days); i++ {
+		if days[i].Equal(days[i-1].AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	var streak models.ChallengeStreak
+	if err := s.db.Where("user_id = ?", userID).First(&streak).Error; err != nil {
+		streak = models.ChallengeStreak{UserID: userID}
+	}
+
+	// The current streak is the run ending at the latest voting day, matching
+	// what updateStreak would have stored had the votes arrived live
+	streak.CurrentStreak = run
+	if longest > streak.LongestStreak {
+		streak.LongestStreak = longest
+	}
+	streak.TotalVotes = int(totalVotes)
+	streak.LastVoteDate = days[len(days)-1]
+
+	s.db.Save(&streak)
+}
+
+// MergeGuestVotes moves a guest's votes to the user who just signed in on
+// that device. Where the user had already voted on the same challenge, the
+// account's vote is kept and the guest's is dropped with its counter
+// decremented, so each person still counts once. The streak is rebuilt
+// afterwards so the guest's voting days count toward it.
+func (s *ChallengeService) MergeGuestVotes(userID uuid.UUID, guestID string) (int, error) {
+	if userID == uuid.Nil || guestID == "" {
+		return 0, nil
+	}
+
+	merged := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Guest votes used to be checked only before insert, so a guest
+		// can have several on one challenge. Keep the first, or moving
+		// them all would break idx_votes_user_challenge.
+		var extra []models.Vote
+		if err := tx.Raw(`
+			DELETE FROM votes dup USING votes keep
+			WHERE dup.guest_id = ? AND dup.user_id = ?
+			AND keep.guest_id = dup.guest_id AND keep.user_id = dup.user_id AND keep.challenge_id = dup.challenge_id
+			AND dup.deleted_at IS NULL AND keep.deleted_at IS NULL
+			AND (keep.created_at, keep.id) < (dup.created_at, dup.id)
+			RETURNING dup.challenge_id, dup.choice`, guestID, uuid.Nil).Scan(&extra).Error; err != nil {
+			return err
+		}
+		for _, v := range extra {
+			if err := decrementVoteCount(tx, &v); err != nil {
+				return err
+			}
+		}
+
+		res := tx.Model(&models.Vote{}).
+			Where("guest_id = ? AND user_id = ?", guestID, uuid.Nil).
+			Where("challenge_id NOT IN (?)", tx.Model(&models.Vote{}).Select("challenge_id").Where("user_id = ?", userID)).
+			Updates(map[string]interface{}{"user_id": userID, "guest_id": ""})
+		if res.Error != nil {
+			return res.Error
+		}
+		merged = int(res.RowsAffected)
+
+		// Whatever is left duplicates a challenge the user already voted on
+		var duplicates []models.Vote
+		if err := tx.Where("guest_id = ? AND user_id = ?", guestID, uuid.Nil).Find(&duplicates).Error; err != nil {
+			return err
+		}
+		for _, v := range duplicates {
+			if err := decrementVoteCount(tx, &v); err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&v).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if merged > 0 {
+		markStatsDirty(s.db, userID, true)
+		s.rebuildStreak(userID)
+		go s.achievements.Record(userID, EventVoteCast, EventStreakUpdated)
+	}
+	return merged, nil
+}
+
+// decrementVoteCount takes a dropped vote off its challenge's counter.
+func decrementVoteCount(tx *gorm.DB, v *models.Vote) error {
+	column := "votes_a"
+	if v.Choice == "B" {
+		column = "votes_b"
+	}
+	return tx.Model(&models.Challenge{}).Where("id = ?", v.ChallengeID).
+		Update(column, gorm.Expr("GREATEST("+column+" - 1, 0)")).Error
+}
+
 // GetGuestVoteCount returns the number of votes a guest made on a given date
 func (s *ChallengeService) GetGuestVoteCount(guestID string, date time.Time) int {
 	startOfDay := date.Truncate(24 * time.Hour)
@@ -411,3 +711,12 @@ func (s *ChallengeService) EnsureMinimumChallenges() error {
 
 	return nil
 }
+
+// votePercentages returns the rounded-down share of each option
+func votePercentages(votesA, votesB int) (int, int) {
+	total := votesA + votesB
+	if total == 0 {
+		return 0, 0
+	}
+	return (votesA * 100) / total, (votesB * 100) / total
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestSyncVoteTime(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		votedAt time.Time
+		want    time.Time
+		reason  string
+	}{
+		{name: "missing", votedAt: time.Time{}, reason: "voted_at is required"},
+		{name: "recent", votedAt: now.Add(-time.Hour), want: now.Add(-time.Hour)},
+		{name: "exactly now", votedAt: now, want: now},
+		{name: "ahead within skew is clamped", votedAt: now.Add(clockSkewAllowance - time.Second), want: now},
+		{name: "ahead at skew limit is clamped", votedAt: now.Add(clockSkewAllowance), want: now},
+		{name: "ahead beyond skew", votedAt: now.Add(clockSkewAllowance + time.Second), reason: "voted_at is in the future"},
+		{name: "at window start", votedAt: now.Add(-offlineVoteWindow), want: now.Add(-offlineVoteWindow)},
+		{name: "before window", votedAt: now.Add(-offlineVoteWindow - time.Second), reason: "voted_at is outside the sync window"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := syncVoteTime(tt.votedAt, now)
+			if reason != tt.reason {
+				t.Fatalf("reason = %q, want %q", reason, tt.reason)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("votedAt = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVoteEntitlement(t *testing.T) {
+	subs := NewSubscriptionService(nil, &config.Config{PremiumEntitlementID: "premium", PremiumCategories: "deep,spicy:adult"})
+	s := &ChallengeService{subscriptions: subs}
+
+	tests := []struct {
+		name      string
+		challenge models.Challenge
+		want      string
+	}{
+		{"free category", models.Challenge{Category: "food"}, ""},
+		{"locked category", models.Challenge{Category: "deep"}, "premium"},
+		{"category with its own entitlement", models.Challenge{Category: "Spicy"}, "adult"},
+		{"daily in a locked category", models.Challenge{Category: "deep", IsDaily: true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.voteEntitlement(&tt.challenge); got != tt.want {
+				t.Errorf("voteEntitlement = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeGuestVotesKeepsCountersCorrect(t *testing.T) {
+	db := testDB(t)
+	stats := NewStatsService(db)
+	subs := NewSubscriptionService(db, &config.Config{})
+	s := NewChallengeService(db, nil, subs, NewAchievementService(db, stats, subs, NewLogNotifier()), nil)
+
+	userID := uuid.New()
+	guestID := "guest-" + uuid.NewString()
+	challenges := make([]models.Challenge, 3)
+	for i := range challenges {
+		challenges[i] = models.Challenge{OptionA: "Fly", OptionB: "Be invisible", Category: "test"}
+		if err := db.Create(&challenges[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, c := range challenges {
+			db.Unscoped().Where("challenge_id = ?", c.ID).Delete(&models.Vote{})
+			db.Unscoped().Delete(&c)
+		}
+		db.Where("user_id = ?", userID).Delete(&models.ChallengeStreak{})
+		db.Where("user_id = ?", userID).Delete(&models.UserStats{})
+	})
+
+	start := time.Now().Add(-time.Hour)
+	vote := func(userID uuid.UUID, guestID string, c models.Challenge, choice string, at time.Time) {
+		t.Helper()
+		v := models.Vote{UserID: userID, GuestID: guestID, ChallengeID: c.ID, Choice: choice, CreatedAt: at}
+		if _, err := s.recordVote(&v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// An old client recorded the guest twice on the first challenge
+	vote(uuid.Nil, guestID, challenges[0], "A", start)
+	vote(uuid.Nil, guestID, challenges[0], "B", start.Add(time.Minute))
+	vote(uuid.Nil, guestID, challenges[1], "B", start)
+	// The account already answered the third one
+	vote(uuid.Nil, guestID, challenges[2], "A", start)
+	vote(userID, "", challenges[2], "B", start)
+
+	merged, err := s.MergeGuestVotes(userID, guestID)
+	if err != nil {
+		t.Fatalf("MergeGuestVotes: %v", err)
+	}
+	if merged != 2 {
+		t.Errorf("merged = %d, want 2", merged)
+	}
+
+	want := []struct{ a, b int }{{1, 0}, {0, 1}, {0, 1}}
+	for i, c := range challenges {
+		var got models.Challenge
+		db.First(&got, "id = ?", c.ID)
+		if got.VotesA != want[i].a || got.VotesB != want[i].b {
+			t.Errorf("challenge %d counters = %d/%d, want %d/%d", i, got.VotesA, got.VotesB, want[i].a, want[i].b)
+		}
+		var rows int64
+		db.Model(&models.Vote{}).Where("challenge_id = ?", c.ID).Count(&rows)
+		if int(rows) != got.VotesA+got.VotesB {
+			t.Errorf("challenge %d has %d vote rows for counters %d/%d", i, rows, got.VotesA, got.VotesB)
+		}
+	}
+
+	var first models.Vote
+	if err := db.Where("user_id = ? AND challenge_id = ?", userID, challenges[0].ID).First(&first).Error; err != nil || first.Choice != "A" {
+		t.Errorf("kept vote on the first challenge = %+v, %v; want the guest's first pick", first, err)
+	}
+	var left int64
+	db.Model(&models.Vote{}).Where("guest_id = ?", guestID).Count(&left)
+	if left != 0 {
+		t.Errorf("%d guest votes left after the merge", left)
+	}
+}
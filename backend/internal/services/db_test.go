@@ -0,0 +1,31 @@
+package services
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB connects to the database in TEST_DATABASE_URL and migrates it.
+// Tests that need Postgres are skipped without one; they create their
+// own rows under fresh IDs and delete them afterwards.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	database.DB = db
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const purgeBatchSize = 50
+
+// DeletionService purges accounts whose deletion grace period has ended.
+type DeletionService struct {
+	db      *gorm.DB
+	storage BlobStorage
+	grace   time.Duration
+}
+
+func NewDeletionService(db *gorm.DB, cfg *config.Config, storage BlobStorage) *DeletionService {
+	return &DeletionService{db: db, storage: storage, grace: cfg.AccountDeletionGrace}
+}
+
+// PurgeDue hard-deletes every account past its grace period. Accounts
+// soft-deleted before the grace period existed are purged once they are
+// as old as the grace period.
+func (s *DeletionService) PurgeDue(ctx context.Context) error {
+	now := time.Now()
+
+	for {
+		var users []models.User
+		if err := s.db.Unscoped().
+			Where("(purge_after IS NOT NULL AND purge_after <= ?) OR (deleted_at IS NOT NULL AND deleted_at <= ?)", now, now.Add(-s.grace)).
+			Limit(purgeBatchSize).
+			Find(&users).Error; err != nil {
+			return err
+		}
+
+		purged := 0
+		for i := range users {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := s.purge(&users[i]); err != nil {
+				log.Printf("Failed to purge account %s: %v", users[i].ID, err)
+				continue
+			}
+			purged++
+		}
+
+		// Stop if the batch is done or nothing could be purged (avoid
+		// spinning on rows that keep failing)
+		if len(users) < purgeBatchSize || purged == 0 {
+			return nil
+		}
+	}
+}
+
+// purge removes a user's personal data and writes a deletion receipt.
+// Votes are anonymized rather than deleted so challenge counters stay
+// consistent with the vote rows behind them; shares and install
+// attribution are too, so share analytics don't change.
+func (s *DeletionService) purge(user *models.User) error {
+	counts := map[string]int64{}
+	var blobs []string
+	restored := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Re-check under lock: a login may have restored the account
+		var current models.User
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&current, "id = ?", user.ID).Error; err != nil {
+			return err
+		}
+		due := current.PurgeAfter != nil && !current.PurgeAfter.After(time.Now())
+		if !due && !current.DeletedAt.Valid {
+			restored = true
+			return nil
+		}
+
+		// Record activation while the votes still name the user
+		res := tx.Model(&models.ShareInstall{}).
+			Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"user_id":   uuid.Nil,
+				"activated": gorm.Expr("activated OR EXISTS (SELECT 1 FROM votes WHERE votes.user_id = ?)", user.ID),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		counts["share_installs_anonymized"] = res.RowsAffected
+
+		res = tx.Model(&models.Share{}).Where("user_id = ?", user.ID).Update("user_id", uuid.Nil)
+		if res.Error != nil {
+			return res.Error
+		}
+		counts["shares_anonymized"] = res.RowsAffected
+
+		res = tx.Unscoped().Model(&models.Vote{}).
+			Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{"user_id": uuid.Nil, "guest_id": ""})
+		if res.Error != nil {
+			return res.Error
+		}
+		counts["votes_anonymized"] = res.RowsAffected
+
+		var exports []models.DataExport
+		tx.Where("user_id = ? AND blob_key <> ''", user.ID).Find(&exports)
+		for _, e := range exports {
+			blobs = append(blobs, e.BlobKey)
+		}
+
+		deletes := []struct {
+			name  string
+			model interface{}
+			where string
+		}{
+			{"streaks", &models.ChallengeStreak{}, "user_id = @id"},
+			{"stats", &models.UserStats{}, "user_id = @id"},
+			{"personality_summaries", &models.PersonalitySummary{}, "user_id = @id"},
+			{"data_exports", &models.DataExport{}, "user_id = @id"},
+			{"refresh_tokens", &models.RefreshToken{}, "user_id = @id"},
+			{"email_tokens", &models.EmailToken{}, "user_id = @id"},
+			{"subscriptions", &models.Subscription{}, "user_id = @id"},
+			{"reports", &models.Report{}, "reporter_id = @id"},
+			{"blocks", &models.Block{}, "blocker_id = @id OR blocked_id = @id"},
+			{"friendships", &models.Friendship{}, "requester_id = @id OR addressee_id = @id"},
+			{"achievements", &models.UserAchievement{}, "user_id = @id"},
+			{"achievement_counters", &models.AchievementCounter{}, "user_id = @id"},
+			{"xp_events", &models.XPEvent{}, "user_id = @id"},
+			{"user_xp", &models.UserXP{}, "user_id = @id"},
+			{"season_scores", &models.SeasonScore{}, "user_id = @id"},
+			{"entitlement_grants", &models.EntitlementGrant{}, "user_id = @id"},
+			{"notifications", &models.Notification{}, "user_id = @id"},
+			{"push_tickets", &models.PushTicket{}, "token IN (SELECT token FROM device_tokens WHERE user_id = @id)"},
+			{"device_tokens", &models.DeviceToken{}, "user_id = @id"},
+		}
+		for _, d := range deletes {
+			res := tx.Unscoped().Where(d.where, sql.Named("id", user.ID)).Delete(d.model)
+			if res.Error != nil {
+				return res.Error
+			}
+			counts[d.name] = res.RowsAffected
+		}
+
+		if current.AvatarKey != "" {
+			blobs = append(blobs, current.AvatarKey)
+		}
+		if err := tx.Unscoped().Delete(&current).Error; err != nil {
+			return err
+		}
+
+		hash := sha256.Sum256([]byte(user.ID.String()))
+		requestedAt := current.DeletionRequestedAt
+		if requestedAt == nil && current.DeletedAt.Valid {
+			requestedAt = &current.DeletedAt.Time
+		}
+		return tx.Create(&models.DeletionReceipt{
+			UserHash:    hex.EncodeToString(hash[:]),
+			RequestedAt: requestedAt,
+			PurgedAt:    time.Now(),
+			Counts:      counts,
+		}).Error
+	})
+	if err != nil || restored {
+		return err
+	}
+
+	// Blobs go last: a failed transaction must not leave rows pointing at
+	// deleted files
+	for _, key := range blobs {
+		if err := s.storage.Delete(key); err != nil {
+			log.Printf("Failed to delete blob %s for purged account: %v", key, err)
+		}
+	}
+
+	log.Printf("Purged account %s", user.ID)
+	return nil
+}
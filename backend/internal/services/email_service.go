@@ -0,0 +1,323 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidEmail         = errors.New("invalid email address")
+	ErrEmailTokenInvalid    = errors.New("invalid or expired link")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrEmailResendTooSoon   = errors.New("please wait a minute before requesting another email")
+	ErrEmailUnchanged       = errors.New("new email is the same as the current one")
+)
+
+const (
+	tokenPurposeVerifyEmail = "verify_email"
+	tokenPurposeChangeEmail = "change_email"
+	tokenPurposeResetPass   = "password_reset"
+
+	emailResendInterval = time.Minute
+	passwordResetTTL    = time.Hour
+)
+
+type EmailService struct {
+	db      *gorm.DB
+	mailer  Mailer
+	baseURL string
+	ttl     time.Duration
+}
+
+func NewEmailService(db *gorm.DB, cfg *config.Config, mailer Mailer) *EmailService {
+	return &EmailService{db: db, mailer: mailer, baseURL: cfg.PublicBaseURL, ttl: cfg.EmailVerificationTTL}
+}
+
+// NormalizeEmail validates a bare email address and trims it. Display-name
+// forms ("Name <a@b.c>") are rejected.
+func NormalizeEmail(raw string) (string, error) {
+	email := strings.TrimSpace(raw)
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > 255 {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+// SendVerification emails the user a link confirming their current address.
+func (s *EmailService) SendVerification(user *models.User) error {
+	raw, err := s.issueToken(user.ID, tokenPurposeVerifyEmail, user.Email, s.ttl)
+	if err != nil {
+		return err
+	}
+	return s.send("verify_email", user.Locale, user.Email, EmailData{
+		Email:     user.Email,
+		Link:      s.link("/api/auth/email/verify", raw),
+		ExpiresIn: formatTTL(s.ttl, user.Locale),
+	})
+}
+
+// ResendVerification re-sends the verification email, at most once a minute.
+func (s *EmailService) ResendVerification(userID uuid.UUID) error {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	if err := s.checkResendInterval(userID, tokenPurposeVerifyEmail); err != nil {
+		return err
+	}
+	return s.SendVerification(&user)
+}
+
+// RequestEmailChange sends a confirmation link to the new address. The
+// account keeps its current email until the link is opened.
+func (s *EmailService) RequestEmailChange(userID uuid.UUID, req *dto.ChangeEmailRequest) error {
+	newEmail, err := NormalizeEmail(req.NewEmail)
+	if err != nil {
+		return err
+	}
+
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+	if user.Password != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			return ErrInvalidCredentials
+		}
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return ErrEmailUnchanged
+	}
+	if s.emailTaken(newEmail, userID) {
+		return ErrEmailTaken
+	}
+	if err := s.checkResendInterval(userID, tokenPurposeChangeEmail); err != nil {
+		return err
+	}
+
+	raw, err := s.issueToken(userID, tokenPurposeChangeEmail, newEmail, s.ttl)
+	if err != nil {
+		return err
+	}
+	return s.send("change_email", user.Locale, newEmail, EmailData{
+		Email:     newEmail,
+		Link:      s.link("/api/auth/email/verify", raw),
+		ExpiresIn: formatTTL(s.ttl, user.Locale),
+	})
+}
+
+// Verify consumes a verification or change-email token.
+func (s *EmailService) Verify(rawToken string) (*dto.EmailVerifiedResponse, error) {
+	token, err := s.consumeToken(rawToken, tokenPurposeVerifyEmail, tokenPurposeChangeEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.db.First(&user, "id = ?", token.UserID).Error; err != nil {
+		return nil, ErrEmailTokenInvalid
+	}
+
+	now := time.Now()
+	switch token.Purpose {
+	case tokenPurposeVerifyEmail:
+		// A token for an address the user has since changed away from is stale
+		if !strings.EqualFold(token.Email, user.Email) {
+			return nil, ErrEmailTokenInvalid
+		}
+		if err := s.db.Model(&user).Update("email_verified_at", now).Error; err != nil {
+			return nil, err
+		}
+
+	case tokenPurposeChangeEmail:
+		oldEmail := user.Email
+		if s.emailTaken(token.Email, user.ID) {
+			return nil, ErrEmailTaken
+		}
+		if err := s.db.Model(&user).Updates(map[string]interface{}{
+			"email":             token.Email,
+			"email_verified_at": now,
+		}).Error; err != nil {
+			if isUniqueViolation(err) {
+				return nil, ErrEmailTaken
+			}
+			return nil, err
+		}
+
+		// Let the old address know, in case the change wasn't theirs
+		if err := s.send("email_changed", user.Locale, oldEmail, EmailData{Email: token.Email}); err != nil {
+			log.Printf("Failed to send email change notice to user %s: %v", user.ID, err)
+		}
+		user.Email = token.Email
+	}
+
+	return &dto.EmailVerifiedResponse{Email: user.Email, Verified: true}, nil
+}
+
+// SendPasswordReset emails a reset link if the address belongs to an
+// account. It reports nothing either way so callers can't probe which
+// addresses are registered; requests are throttled silently.
+func (s *EmailService) SendPasswordReset(email string) {
+	var user models.User
+	if err := s.db.Where("email = ?", strings.TrimSpace(email)).First(&user).Error; err != nil {
+		return
+	}
+	if s.checkResendInterval(user.ID, tokenPurposeResetPass) != nil {
+		return
+	}
+
+	raw, err := s.issueToken(user.ID, tokenPurposeResetPass, user.Email, passwordResetTTL)
+	if err != nil {
+		log.Printf("Failed to issue password reset token for user %s: %v", user.ID, err)
+		return
+	}
+	if err := s.send("password_reset", user.Locale, user.Email, EmailData{
+		Link:      s.link("/api/auth/password/reset", raw),
+		ExpiresIn: formatTTL(passwordResetTTL, user.Locale),
+	}); err != nil {
+		log.Printf("Failed to send password reset email to user %s: %v", user.ID, err)
+	}
+}
+
+// PasswordResetAppLink is the deep link that hands a reset token to the
+// app; the web reset page offers it before falling back to its own form.
+func PasswordResetAppLink(token string) template.URL {
+	return template.URL(fmt.Sprintf("%s://reset-password?token=%s", appScheme, url.QueryEscape(token)))
+}
+
+// ConsumePasswordReset redeems a reset token and returns the user it was
+// issued to. Tokens sent to an address the user has since changed are void.
+func (s *EmailService) ConsumePasswordReset(rawToken string) (*models.User, error) {
+	token, err := s.consumeToken(rawToken, tokenPurposeResetPass)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.db.First(&user, "id = ?", token.UserID).Error; err != nil {
+		return nil, ErrEmailTokenInvalid
+	}
+	if !strings.EqualFold(token.Email, user.Email) {
+		return nil, ErrEmailTokenInvalid
+	}
+	return &user, nil
+}
+
+func (s *EmailService) emailTaken(email string, exceptUserID uuid.UUID) bool {
+	var count int64
+	s.db.Unscoped().Model(&models.User{}).
+		Where("LOWER(email) = LOWER(?) AND id <> ?", email, exceptUserID).
+		Count(&count)
+	return count > 0
+}
+
+func (s *EmailService) checkResendInterval(userID uuid.UUID, purpose string) error {
+	var count int64
+	s.db.Model(&models.EmailToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at > ?", userID, purpose, time.Now().Add(-emailResendInterval)).
+		Count(&count)
+	if count > 0 {
+		return ErrEmailResendTooSoon
+	}
+	return nil
+}
+
+// issueToken creates a new token, replacing any unused ones for the same
+// purpose so only the latest link works.
+func (s *EmailService) issueToken(userID uuid.UUID, purpose, email string, ttl time.Duration) (string, error) {
+	rawBytes := make([]byte, 32)
+	if _, err := rand.Read(rawBytes); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	raw := base64.RawURLEncoding.EncodeToString(rawBytes)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Delete(&models.EmailToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.EmailToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			Email:     email,
+			TokenHash: hashToken(raw),
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// consumeToken marks a token used, failing if it is unknown, expired,
+// already used or issued for another purpose.
+func (s *EmailService) consumeToken(raw string, purposes ...string) (*models.EmailToken, error) {
+	if raw == "" {
+		return nil, ErrEmailTokenInvalid
+	}
+
+	var token models.EmailToken
+	if err := s.db.Where("token_hash = ? AND purpose IN ?", hashToken(raw), purposes).First(&token).Error; err != nil {
+		return nil, ErrEmailTokenInvalid
+	}
+
+	now := time.Now()
+	res := s.db.Model(&models.EmailToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+		Update("used_at", now)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrEmailTokenInvalid
+	}
+	return &token, nil
+}
+
+func (s *EmailService) link(path, token string) template.URL {
+	return template.URL(fmt.Sprintf("%s%s?token=%s", s.baseURL, path, url.QueryEscape(token)))
+}
+
+func (s *EmailService) send(template, locale, to string, data EmailData) error {
+	msg, err := renderEmail(template, locale, to, data)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(msg)
+}
+
+// formatTTL renders a link lifetime in whole hours, localized.
+func formatTTL(d time.Duration, locale string) string {
+	hours := int(d.Round(time.Hour) / time.Hour)
+	if hours < 1 {
+		hours = 1
+	}
+	switch NormalizeLocale(locale) {
+	case "tr":
+		return fmt.Sprintf("%d saat", hours)
+	}
+	if hours == 1 {
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d hours", hours)
+}
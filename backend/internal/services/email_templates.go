@@ -0,0 +1,161 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+const defaultLocale = "en"
+
+// EmailData is the data available to email templates.
+type EmailData struct {
+	Email     string           // Address the message concerns (e.g. the new address)
+	Link      htmltemplate.URL // Action link; built server-side, may use the app scheme
+	ExpiresIn string           // Human-readable link lifetime, e.g. "48 hours"
+}
+
+// emailTemplate holds one localized email. Body is HTML placed inside the
+// shared layout; Text is the plain-text alternative.
+type emailTemplate struct {
+	Subject string
+	Text    string
+	Body    string
+}
+
+var emailTemplates = map[string]map[string]emailTemplate{
+	"verify_email": {
+		"en": {
+			Subject: "Confirm your email for WouldYou",
+			Text:    "Welcome to WouldYou!\n\nConfirm your email address by opening this link:\n{{.Link}}\n\nThe link expires in {{.ExpiresIn}}. If you didn't create an account, you can ignore this email.",
+			Body:    `<h1>Welcome to WouldYou!</h1><p>Confirm your email address to finish setting up your account.</p><p><a class="button" href="{{.Link}}">Confirm email</a></p><p class="muted">The link expires in {{.ExpiresIn}}. If you didn't create an account, you can ignore this email.</p>`,
+		},
+		"tr": {
+			Subject: "WouldYou e-posta adresini doğrula",
+			Text:    "WouldYou'ya hoş geldin!\n\nE-posta adresini doğrulamak için bu bağlantıyı aç:\n{{.Link}}\n\nBağlantı {{.ExpiresIn}} içinde geçerliliğini yitirir. Hesap oluşturmadıysan bu e-postayı yok sayabilirsin.",
+			Body:    `<h1>WouldYou'ya hoş geldin!</h1><p>Hesabını tamamlamak için e-posta adresini doğrula.</p><p><a class="button" href="{{.Link}}">E-postayı doğrula</a></p><p class="muted">Bağlantı {{.ExpiresIn}} içinde geçerliliğini yitirir. Hesap oluşturmadıysan bu e-postayı yok sayabilirsin.</p>`,
+		},
+	},
+	"change_email": {
+		"en": {
+			Subject: "Confirm your new email for WouldYou",
+			Text:    "You asked to change your WouldYou email to {{.Email}}.\n\nConfirm the change by opening this link:\n{{.Link}}\n\nThe link expires in {{.ExpiresIn}}. If you didn't ask for this, ignore this email and nothing will change.",
+			Body:    `<h1>Confirm your new email</h1><p>You asked to change your WouldYou email to <strong>{{.Email}}</strong>.</p><p><a class="button" href="{{.Link}}">Confirm change</a></p><p class="muted">The link expires in {{.ExpiresIn}}. If you didn't ask for this, ignore this email and nothing will change.</p>`,
+		},
+		"tr": {
+			Subject: "WouldYou yeni e-posta adresini onayla",
+			Text:    "WouldYou e-posta adresini {{.Email}} olarak değiştirmek istedin.\n\nDeğişikliği onaylamak için bu bağlantıyı aç:\n{{.Link}}\n\nBağlantı {{.ExpiresIn}} içinde geçerliliğini yitirir. Bu isteği sen yapmadıysan bu e-postayı yok say; hiçbir şey değişmeyecek.",
+			Body:    `<h1>Yeni e-posta adresini onayla</h1><p>WouldYou e-posta adresini <strong>{{.Email}}</strong> olarak değiştirmek istedin.</p><p><a class="button" href="{{.Link}}">Değişikliği onayla</a></p><p class="muted">Bağlantı {{.ExpiresIn}} içinde geçerliliğini yitirir. Bu isteği sen yapmadıysan bu e-postayı yok say; hiçbir şey değişmeyecek.</p>`,
+		},
+	},
+	"email_changed": {
+		"en": {
+			Subject: "Your WouldYou email was changed",
+			Text:    "The email address on your WouldYou account was changed to {{.Email}}.\n\nIf you didn't make this change, contact support right away.",
+			Body:    `<h1>Your email was changed</h1><p>The email address on your WouldYou account was changed to <strong>{{.Email}}</strong>.</p><p class="muted">If you didn't make this change, contact support right away.</p>`,
+		},
+		"tr": {
+			Subject: "WouldYou e-posta adresin değiştirildi",
+			Text:    "WouldYou hesabındaki e-posta adresi {{.Email}} olarak değiştirildi.\n\nBu değişikliği sen yapmadıysan hemen destek ekibiyle iletişime geç.",
+			Body:    `<h1>E-posta adresin değiştirildi</h1><p>WouldYou hesabındaki e-posta adresi <strong>{{.Email}}</strong> olarak değiştirildi.</p><p class="muted">Bu değişikliği sen yapmadıysan hemen destek ekibiyle iletişime geç.</p>`,
+		},
+	},
+	"password_reset": {
+		"en": {
+			Subject: "Reset your WouldYou password",
+			Text:    "Someone asked to reset the password for your WouldYou account.\n\nOpen this link to choose a new password in the app or in your browser:\n{{.Link}}\n\nThe link expires in {{.ExpiresIn}} and works once. If you didn't ask for this, you can ignore this email.",
+			Body:    `<h1>Reset your password</h1><p>Someone asked to reset the password for your WouldYou account. Open this link to choose a new one in the app or in your browser.</p><p><a class="button" href="{{.Link}}">Reset password</a></p><p class="muted">The link expires in {{.ExpiresIn}} and works once. If you didn't ask for this, you can ignore this email.</p>`,
+		},
+		"tr": {
+			Subject: "WouldYou şifreni sıfırla",
+			Text:    "WouldYou hesabının şifresini sıfırlama isteği aldık.\n\nYeni bir şifreyi uygulamada ya da tarayıcında belirlemek için bu bağlantıyı aç:\n{{.Link}}\n\nBağlantı {{.ExpiresIn}} içinde geçerliliğini yitirir ve yalnızca bir kez kullanılabilir. Bu isteği sen yapmadıysan bu e-postayı yok sayabilirsin.",
+			Body:    `<h1>Şifreni sıfırla</h1><p>WouldYou hesabının şifresini sıfırlama isteği aldık. Yeni bir şifreyi uygulamada ya da tarayıcında belirlemek için bu bağlantıyı aç.</p><p><a class="button" href="{{.Link}}">Şifreyi sıfırla</a></p><p class="muted">Bağlantı {{.ExpiresIn}} içinde geçerliliğini yitirir ve yalnızca bir kez kullanılabilir. Bu isteği sen yapmadıysan bu e-postayı yok sayabilirsin.</p>`,
+		},
+	},
+}
+
+const emailLayout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<style>
+body{margin:0;padding:24px;background:#0F0F1A;font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,sans-serif;color:#FFFFFF}
+.card{max-width:480px;margin:0 auto;padding:32px;border-radius:16px;background:#1A1A2E}
+h1{margin:0 0 16px;font-size:22px}
+p{line-height:1.5}
+.button{display:inline-block;padding:12px 24px;border-radius:12px;background:#6C5CE7;color:#FFFFFF;text-decoration:none;font-weight:600}
+.muted{color:#A0A0B8;font-size:13px}
+</style>
+</head>
+<body><div class="card">{{template "body" .}}</div></body>
+</html>`
+
+type compiledEmail struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// compiledEmails is keyed by template name, then locale.
+var compiledEmails = compileEmails()
+
+func compileEmails() map[string]map[string]*compiledEmail {
+	compiled := make(map[string]map[string]*compiledEmail, len(emailTemplates))
+	for name, locales := range emailTemplates {
+		compiled[name] = make(map[string]*compiledEmail, len(locales))
+		for locale, t := range locales {
+			html := htmltemplate.Must(htmltemplate.New("layout").Parse(emailLayout))
+			htmltemplate.Must(html.New("body").Parse(t.Body))
+			compiled[name][locale] = &compiledEmail{
+				subject: texttemplate.Must(texttemplate.New("subject").Parse(t.Subject)),
+				text:    texttemplate.Must(texttemplate.New("text").Parse(t.Text)),
+				html:    html,
+			}
+		}
+	}
+	return compiled
+}
+
+// renderEmail renders a template in the closest supported locale.
+func renderEmail(name, locale, to string, data EmailData) (*EmailMessage, error) {
+	locales, ok := compiledEmails[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+	t, ok := locales[NormalizeLocale(locale)]
+	if !ok {
+		t = locales[defaultLocale]
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return nil, err
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if err := t.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return nil, err
+	}
+
+	return &EmailMessage{To: to, Subject: subject.String(), Text: text.String(), HTML: html.String()}, nil
+}
+
+// NormalizeLocale reduces a locale or Accept-Language value ("tr-TR",
+// "tr_TR", "tr;q=0.9") to a supported language code.
+func NormalizeLocale(raw string) string {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if i := strings.IndexAny(raw, ",;"); i >= 0 {
+		raw = raw[:i]
+	}
+	if i := strings.IndexAny(raw, "-_"); i >= 0 {
+		raw = raw[:i]
+	}
+	if _, ok := emailTemplates["verify_email"][raw]; ok {
+		return raw
+	}
+	return defaultLocale
+}
@@ -0,0 +1,541 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExportNotFound    = errors.New("export not found")
+	ErrExportNotReady    = errors.New("export is not ready")
+	ErrExportLinkInvalid = errors.New("download link is invalid or has expired")
+	ErrExportRateLimited = errors.New("an export was already created in the last 24 hours")
+)
+
+const (
+	exportURLTTL = 15 * time.Minute
+	// One new export per user per day
+	exportCooldown = 24 * time.Hour
+	// Exports stuck in "processing" this long (e.g. after a crash) are retried
+	exportStaleAfter = 30 * time.Minute
+	exportBatchSize  = 10
+)
+
+// userDataArchive is the export.json document inside the zip.
+type userDataArchive struct {
+	ExportedAt    time.Time             `json:"exported_at"`
+	Profile       exportProfile         `json:"profile"`
+	Votes         []exportVote          `json:"votes"`
+	Streak        *exportStreak         `json:"streak"`
+	Subscriptions []models.Subscription `json:"subscriptions"`
+	Reports       []models.Report       `json:"reports_filed"`
+	Blocks        []exportBlock         `json:"blocks"`
+	Shares        []exportShare         `json:"shares"`
+	Friends       []exportFriend        `json:"friends"`
+	Achievements  []exportAchievement   `json:"achievements"`
+	TotalXP       int                   `json:"total_xp"`
+	Seasons       []exportSeason        `json:"seasons"`
+	Notifications []exportNotification  `json:"notifications"`
+	Devices       []exportDevice        `json:"devices"`
+}
+
+type exportProfile struct {
+	ID          uuid.UUID `json:"id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	DisplayName string    `json:"display_name"`
+	AvatarFile  string    `json:"avatar_file,omitempty"` // Path inside the archive
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type exportVote struct {
+	ChallengeID uuid.UUID `json:"challenge_id"`
+	OptionA     string    `json:"option_a"`
+	OptionB     string    `json:"option_b"`
+	Category    string    `json:"category"`
+	Choice      string    `json:"choice"`
+	VotedAt     time.Time `json:"voted_at"`
+}
+
+type exportStreak struct {
+	CurrentStreak int       `json:"current_streak"`
+	LongestStreak int       `json:"longest_streak"`
+	TotalVotes    int       `json:"total_votes"`
+	LastVoteDate  time.Time `json:"last_vote_date"`
+}
+
+type exportBlock struct {
+	BlockedUserID uuid.UUID `json:"blocked_user_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type exportFriend struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Status    string    `json:"status"` // "friends", "outgoing" or "incoming"
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type exportAchievement struct {
+	AchievementID string    `json:"achievement_id"`
+	UnlockedAt    time.Time `json:"unlocked_at"`
+}
+
+type exportSeason struct {
+	SeasonID  string `json:"season_id"`
+	XP        int    `json:"xp"`
+	FinalRank *int   `json:"final_rank,omitempty"`
+	Prize     string `json:"prize,omitempty"`
+}
+
+type exportNotification struct {
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type exportDevice struct {
+	Provider  string    `json:"provider"`
+	Platform  string    `json:"platform"`
+	Timezone  string    `json:"timezone"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type exportShare struct {
+	Code        string    `json:"code"`
+	ChallengeID uuid.UUID `json:"challenge_id"`
+	Platform    string    `json:"platform"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ExportService struct {
+	db        *gorm.DB
+	storage   BlobStorage
+	notifier  Notifier
+	baseURL   string
+	secret    []byte
+	retention time.Duration
+}
+
+func NewExportService(db *gorm.DB, cfg *config.Config, storage BlobStorage, notifier Notifier) *ExportService {
+	// Derive a dedicated key so download signatures can't be confused with JWTs
+	mac := hmac.New(sha256.New, []byte(cfg.JWTSecret))
+	mac.Write([]byte("data-export-url"))
+
+	return &ExportService{
+		db:        db,
+		storage:   storage,
+		notifier:  notifier,
+		baseURL:   cfg.PublicBaseURL,
+		secret:    mac.Sum(nil),
+		retention: cfg.DataExportRetention,
+	}
+}
+
+// RequestExport queues a new export. An export that is still pending or
+// processing is returned as is rather than queuing another.
+func (s *ExportService) RequestExport(userID uuid.UUID) (*dto.DataExportResponse, error) {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var existing models.DataExport
+	err := s.db.Where("user_id = ? AND status IN ?", userID, []string{"pending", "processing"}).
+		Order("created_at DESC").First(&existing).Error
+	if err == nil {
+		return s.toResponse(&existing), nil
+	}
+
+	var recent int64
+	s.db.Model(&models.DataExport{}).
+		Where("user_id = ? AND status = ? AND created_at > ?", userID, "ready", time.Now().Add(-exportCooldown)).
+		Count(&recent)
+	if recent > 0 {
+		return nil, ErrExportRateLimited
+	}
+
+	export := models.DataExport{UserID: userID, Status: "pending"}
+	if err := s.db.Create(&export).Error; err != nil {
+		return nil, err
+	}
+
+	go s.process(export.ID)
+
+	return s.toResponse(&export), nil
+}
+
+func (s *ExportService) ListExports(userID uuid.UUID) ([]dto.DataExportResponse, error) {
+	var exports []models.DataExport
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(20).Find(&exports).Error; err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.DataExportResponse, len(exports))
+	for i := range exports {
+		resp[i] = *s.toResponse(&exports[i])
+	}
+	return resp, nil
+}
+
+func (s *ExportService) GetExport(userID, exportID uuid.UUID) (*dto.DataExportResponse, error) {
+	var export models.DataExport
+	if err := s.db.Where("id = ? AND user_id = ?", exportID, userID).First(&export).Error; err != nil {
+		return nil, ErrExportNotFound
+	}
+	return s.toResponse(&export), nil
+}
+
+// OpenDownload verifies a signed download link and opens the archive.
+func (s *ExportService) OpenDownload(exportID uuid.UUID, expires, signature string) (io.ReadCloser, string, error) {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return nil, "", ErrExportLinkInvalid
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, s.sign(exportID, exp)) {
+		return nil, "", ErrExportLinkInvalid
+	}
+
+	var export models.DataExport
+	if err := s.db.First(&export, "id = ?", exportID).Error; err != nil {
+		return nil, "", ErrExportNotFound
+	}
+	if export.Status != "ready" {
+		return nil, "", ErrExportNotReady
+	}
+
+	rc, err := s.storage.Get(export.BlobKey)
+	if err != nil {
+		return nil, "", ErrExportNotFound
+	}
+	filename := fmt.Sprintf("wouldyou-export-%s.zip", export.CreatedAt.UTC().Format("2006-01-02"))
+	return rc, filename, nil
+}
+
+// ProcessPending builds queued exports. It also picks up exports left in
+// "processing" by a crashed or restarted server.
+func (s *ExportService) ProcessPending(ctx context.Context) error {
+	s.db.Model(&models.DataExport{}).
+		Where("status = ? AND started_at < ?", "processing", time.Now().Add(-exportStaleAfter)).
+		Update("status", "pending")
+
+	var ids []uuid.UUID
+	if err := s.db.Model(&models.DataExport{}).
+		Where("status = ?", "pending").
+		Order("created_at ASC").
+		Limit(exportBatchSize).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.process(id)
+	}
+	return nil
+}
+
+// CleanupExpired deletes archives past their retention.
+func (s *ExportService) CleanupExpired(ctx context.Context) error {
+	var exports []models.DataExport
+	if err := s.db.Where("status = ? AND expires_at < ?", "ready", time.Now()).
+		Limit(100).Find(&exports).Error; err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.storage.Delete(export.BlobKey); err != nil {
+			log.Printf("Failed to delete export %s: %v", export.ID, err)
+			continue
+		}
+		s.db.Model(&export).Updates(map[string]interface{}{"status": "expired", "blob_key": ""})
+	}
+	return nil
+}
+
+// process claims a pending export and builds it. Claiming with a
+// conditional update lets the request goroutine and the scheduler race
+// safely.
+func (s *ExportService) process(exportID uuid.UUID) {
+	now := time.Now()
+	res := s.db.Model(&models.DataExport{}).
+		Where("id = ? AND status = ?", exportID, "pending").
+		Updates(map[string]interface{}{"status": "processing", "started_at": now})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return
+	}
+
+	var export models.DataExport
+	if err := s.db.First(&export, "id = ?", exportID).Error; err != nil {
+		return
+	}
+
+	if err := s.build(&export); err != nil {
+		log.Printf("Data export %s failed: %v", export.ID, err)
+		s.db.Model(&export).Updates(map[string]interface{}{"status": "failed", "error": truncate(err.Error(), 500)})
+		return
+	}
+
+	s.notifier.Notify(export.UserID, Notification{
+		Type:  "data_export_ready",
+		Title: "Your data export is ready",
+		Body:  "Download it from your account settings before it expires.",
+		Data:  map[string]string{"export_id": export.ID.String()},
+	})
+}
+
+func (s *ExportService) build(export *models.DataExport) error {
+	archive, avatarKey, err := s.collect(export.UserID)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	if avatarKey != "" {
+		if rc, err := s.storage.Get(avatarKey); err == nil {
+			name := "avatar" + path.Ext(avatarKey)
+			w, err := zw.Create(name)
+			if err == nil {
+				_, err = io.Copy(w, rc)
+			}
+			rc.Close()
+			if err != nil {
+				return err
+			}
+			archive.Profile.AvatarFile = name
+		}
+	}
+
+	w, err := zw.Create("export.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(archive); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	key := fmt.Sprintf("exports/%s/%s.zip", export.UserID, hex.EncodeToString(suffix))
+	size := int64(buf.Len())
+	if err := s.storage.Put(key, &buf, "application/zip"); err != nil {
+		return fmt.Errorf("failed to store export: %w", err)
+	}
+
+	now := time.Now()
+	expires := now.Add(s.retention)
+	return s.db.Model(export).Updates(map[string]interface{}{
+		"status":       "ready",
+		"blob_key":     key,
+		"size_bytes":   size,
+		"completed_at": now,
+		"expires_at":   expires,
+	}).Error
+}
+
+// collect gathers everything stored about the user. It returns the avatar
+// blob key separately so the file can be bundled alongside the JSON.
+func (s *ExportService) collect(userID uuid.UUID) (*userDataArchive, string, error) {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, "", ErrUserNotFound
+	}
+
+	archive := &userDataArchive{
+		ExportedAt: time.Now().UTC(),
+		Profile: exportProfile{
+			ID:          user.ID,
+			Email:       user.Email,
+			Role:        user.Role,
+			DisplayName: user.DisplayName,
+			CreatedAt:   user.CreatedAt,
+			UpdatedAt:   user.UpdatedAt,
+		},
+		Votes:         []exportVote{},
+		Subscriptions: []models.Subscription{},
+		Reports:       []models.Report{},
+		Blocks:        []exportBlock{},
+		Shares:        []exportShare{},
+		Friends:       []exportFriend{},
+		Achievements:  []exportAchievement{},
+		Seasons:       []exportSeason{},
+		Notifications: []exportNotification{},
+		Devices:       []exportDevice{},
+	}
+
+	// LEFT JOIN keeps votes on challenges that have since been removed
+	if err := s.db.Table("votes").
+		Select("votes.challenge_id, challenges.option_a, challenges.option_b, challenges.category, votes.choice, votes.created_at AS voted_at").
+		Joins("LEFT JOIN challenges ON challenges.id = votes.challenge_id").
+		Where("votes.user_id = ? AND votes.deleted_at IS NULL", userID).
+		Order("votes.created_at ASC").
+		Scan(&archive.Votes).Error; err != nil {
+		return nil, "", err
+	}
+
+	var streak models.ChallengeStreak
+	if s.db.Where("user_id = ?", userID).First(&streak).Error == nil {
+		archive.Streak = &exportStreak{
+			CurrentStreak: streak.CurrentStreak,
+			LongestStreak: streak.LongestStreak,
+			TotalVotes:    streak.TotalVotes,
+			LastVoteDate:  streak.LastVoteDate,
+		}
+	}
+
+	if err := s.db.Where("user_id = ?", userID).Find(&archive.Subscriptions).Error; err != nil {
+		return nil, "", err
+	}
+	if err := s.db.Where("reporter_id = ?", userID).Find(&archive.Reports).Error; err != nil {
+		return nil, "", err
+	}
+
+	var blocks []models.Block
+	if err := s.db.Where("blocker_id = ?", userID).Find(&blocks).Error; err != nil {
+		return nil, "", err
+	}
+	for _, b := range blocks {
+		archive.Blocks = append(archive.Blocks, exportBlock{BlockedUserID: b.BlockedID, CreatedAt: b.CreatedAt})
+	}
+
+	var shares []models.Share
+	if err := s.db.Where("user_id = ?", userID).Find(&shares).Error; err != nil {
+		return nil, "", err
+	}
+	for _, sh := range shares {
+		archive.Shares = append(archive.Shares, exportShare{
+			Code:        sh.Code,
+			ChallengeID: sh.ChallengeID,
+			Platform:    sh.Platform,
+			CreatedAt:   sh.CreatedAt,
+		})
+	}
+
+	var friendships []models.Friendship
+	if err := s.db.Where("requester_id = ? OR addressee_id = ?", userID, userID).Find(&friendships).Error; err != nil {
+		return nil, "", err
+	}
+	for i := range friendships {
+		f := &friendships[i]
+		archive.Friends = append(archive.Friends, exportFriend{
+			UserID:    otherUser(f, userID),
+			Status:    friendStatusOf(userID, f),
+			CreatedAt: f.CreatedAt,
+		})
+	}
+
+	var unlocks []models.UserAchievement
+	if err := s.db.Where("user_id = ?", userID).Order("unlocked_at").Find(&unlocks).Error; err != nil {
+		return nil, "", err
+	}
+	for _, u := range unlocks {
+		archive.Achievements = append(archive.Achievements, exportAchievement{AchievementID: u.AchievementID, UnlockedAt: u.UnlockedAt})
+	}
+
+	var xp models.UserXP
+	if s.db.Where("user_id = ?", userID).First(&xp).Error == nil {
+		archive.TotalXP = xp.TotalXP
+	}
+
+	var scores []models.SeasonScore
+	if err := s.db.Where("user_id = ?", userID).Order("season_id").Find(&scores).Error; err != nil {
+		return nil, "", err
+	}
+	for _, sc := range scores {
+		archive.Seasons = append(archive.Seasons, exportSeason{SeasonID: sc.SeasonID, XP: sc.XP, FinalRank: sc.FinalRank, Prize: sc.Prize})
+	}
+
+	var notifications []models.Notification
+	if err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&notifications).Error; err != nil {
+		return nil, "", err
+	}
+	for _, n := range notifications {
+		archive.Notifications = append(archive.Notifications, exportNotification{
+			Type:      n.Type,
+			Title:     n.Title,
+			Body:      n.Body,
+			ReadAt:    n.ReadAt,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+
+	var devices []models.DeviceToken
+	if err := s.db.Where("user_id = ?", userID).Find(&devices).Error; err != nil {
+		return nil, "", err
+	}
+	for _, d := range devices {
+		archive.Devices = append(archive.Devices, exportDevice{Provider: d.Provider, Platform: d.Platform, Timezone: d.Timezone, CreatedAt: d.CreatedAt})
+	}
+
+	return archive, user.AvatarKey, nil
+}
+
+func (s *ExportService) toResponse(export *models.DataExport) *dto.DataExportResponse {
+	resp := &dto.DataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		SizeBytes:   export.SizeBytes,
+		ExpiresAt:   export.ExpiresAt,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+	}
+	if export.Status == "ready" {
+		exp := time.Now().Add(exportURLTTL)
+		if export.ExpiresAt != nil && export.ExpiresAt.Before(exp) {
+			exp = *export.ExpiresAt
+		}
+		resp.DownloadURL = fmt.Sprintf("%s/api/exports/%s/download?expires=%d&sig=%s",
+			s.baseURL, export.ID, exp.Unix(), hex.EncodeToString(s.sign(export.ID, exp.Unix())))
+		resp.URLExpires = &exp
+	}
+	return resp
+}
+
+func (s *ExportService) sign(exportID uuid.UUID, expires int64) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s:%d", exportID, expires)
+	return mac.Sum(nil)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	feedTitle       = "WouldYou — Daily Would You Rather"
+	feedDescription = "Today's Would You Rather question, plus how everyone answered yesterday."
+	feedDateLayout  = "2006-01-02"
+)
+
+// DailyFeedEntry is one daily challenge together with the final results of
+// the daily that ran the day before it.
+type DailyFeedEntry struct {
+	Challenge models.Challenge
+	Previous  *models.Challenge
+	Permalink string
+}
+
+// DailyFeed is the source data shared by the RSS, Atom and JSON renderings.
+type DailyFeed struct {
+	Entries      []DailyFeedEntry
+	LastModified time.Time
+}
+
+// FeedService builds public syndication feeds from the daily Challenge rows.
+type FeedService struct {
+	db               *gorm.DB
+	challengeService *ChallengeService
+	baseURL          string
+}
+
+func NewFeedService(db *gorm.DB, cs *ChallengeService, cfg *config.Config) *FeedService {
+	return &FeedService{db: db, challengeService: cs, baseURL: cfg.PublicBaseURL}
+}
+
+// DailyPermalink returns the public URL of the daily challenge for a date.
+func (s *FeedService) DailyPermalink(date time.Time) string {
+	return fmt.Sprintf("%s/daily/%s", s.baseURL, date.UTC().Format(feedDateLayout))
+}
+
+// GetDailyFeed returns the most recent daily challenges, newest first.
+func (s *FeedService) GetDailyFeed(limit int) (*DailyFeed, error) {
+	// Make sure today's daily exists so feed readers never lag a day behind
+	if _, err := s.challengeService.GetDailyChallenge(); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row so the oldest entry still gets its "yesterday"
+	var challenges []models.Challenge
+	if err := s.db.Where("is_daily = ?", true).
+		Order("daily_date DESC").
+		Limit(limit + 1).
+		Find(&challenges).Error; err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]*models.Challenge, len(challenges))
+	for i := range challenges {
+		byDate[challenges[i].DailyDate.UTC().Format(feedDateLayout)] = &challenges[i]
+	}
+
+	feed := &DailyFeed{}
+	for i, ch := range challenges {
+		if i == limit {
+			break
+		}
+
+		entry := DailyFeedEntry{
+			Challenge: ch,
+			Permalink: s.DailyPermalink(ch.DailyDate),
+		}
+		prevKey := ch.DailyDate.UTC().AddDate(0, 0, -1).Format(feedDateLayout)
+		if prev, ok := byDate[prevKey]; ok {
+			entry.Previous = prev
+		}
+		feed.Entries = append(feed.Entries, entry)
+
+		// A new daily or late votes on yesterday's daily both change the feed
+		if ch.DailyDate.After(feed.LastModified) {
+			feed.LastModified = ch.DailyDate
+		}
+		if entry.Previous != nil && entry.Previous.UpdatedAt.After(feed.LastModified) {
+			feed.LastModified = entry.Previous.UpdatedAt
+		}
+	}
+
+	feed.LastModified = feed.LastModified.UTC().Truncate(time.Second)
+	return feed, nil
+}
+
+// RenderRSS renders the feed as RSS 2.0.
+func (s *FeedService) RenderRSS(feed *DailyFeed) ([]byte, error) {
+	rss := dto.RSSFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: dto.RSSChannel{
+			Title:         feedTitle,
+			Link:          s.baseURL,
+			Description:   feedDescription,
+			Language:      "en",
+			LastBuildDate: feed.LastModified.Format(time.RFC1123Z),
+			TTL:           60,
+			AtomLink: dto.RSSLink{
+				Href: s.baseURL + "/feeds/daily.rss",
+				Rel:  "self",
+				Type: "application/rss+xml",
+			},
+		},
+	}
+
+	for _, e := range feed.Entries {
+		rss.Channel.Items = append(rss.Channel.Items, dto.RSSItem{
+			Title:       feedEntryTitle(&e.Challenge),
+			Link:        e.Permalink,
+			Description: feedEntrySummary(&e),
+			Category:    e.Challenge.Category,
+			GUID:        dto.RSSGUID{IsPermaLink: true, Value: e.Permalink},
+			PubDate:     e.Challenge.DailyDate.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return marshalXML(rss)
+}
+
+// RenderAtom renders the feed as Atom 1.0.
+func (s *FeedService) RenderAtom(feed *DailyFeed) ([]byte, error) {
+	atom := dto.AtomFeed{
+		Title:   feedTitle,
+		ID:      s.baseURL + "/feeds/daily.atom",
+		Updated: feed.LastModified.Format(time.RFC3339),
+		Links: []dto.AtomLink{
+			{Href: s.baseURL + "/feeds/daily.atom", Rel: "self", Type: "application/atom+xml"},
+			{Href: s.baseURL, Rel: "alternate", Type: "text/html"},
+		},
+		Author: dto.AtomPerson{Name: "WouldYou"},
+	}
+
+	for _, e := range feed.Entries {
+		updated := e.Challenge.DailyDate
+		if e.Previous != nil && e.Previous.UpdatedAt.After(updated) {
+			updated = e.Previous.UpdatedAt
+		}
+
+		entry := dto.AtomEntry{
+			Title:     feedEntryTitle(&e.Challenge),
+			ID:        e.Permalink,
+			Link:      dto.AtomLink{Href: e.Permalink, Rel: "alternate", Type: "text/html"},
+			Published: e.Challenge.DailyDate.UTC().Format(time.RFC3339),
+			Updated:   updated.UTC().Format(time.RFC3339),
+			Summary:   dto.AtomText{Type: "text", Value: feedEntrySummary(&e)},
+		}
+		if e.Challenge.Category != "" {
+			entry.Category = &dto.AtomCategory{Term: e.Challenge.Category}
+		}
+		atom.Entries = append(atom.Entries, entry)
+	}
+
+	return marshalXML(atom)
+}
+
+// RenderJSON renders the feed as JSON Feed 1.1.
+func (s *FeedService) RenderJSON(feed *DailyFeed) ([]byte, error) {
+	jf := dto.JSONFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feedTitle,
+		HomePageURL: s.baseURL,
+		FeedURL:     s.baseURL + "/feeds/daily.json",
+		Description: feedDescription,
+		Language:    "en",
+		Items:       make([]dto.JSONFeedItem, 0, len(feed.Entries)),
+	}
+
+	for _, e := range feed.Entries {
+		modified := e.Challenge.DailyDate
+		if e.Previous != nil && e.Previous.UpdatedAt.After(modified) {
+			modified = e.Previous.UpdatedAt
+		}
+
+		item := dto.JSONFeedItem{
+			ID:            e.Permalink,
+			URL:           e.Permalink,
+			Title:         feedEntryTitle(&e.Challenge),
+			ContentText:   feedEntrySummary(&e),
+			DatePublished: e.Challenge.DailyDate.UTC().Format(time.RFC3339),
+			DateModified:  modified.UTC().Format(time.RFC3339),
+			WouldYou: dto.JSONFeedChallenge{
+				ID:       e.Challenge.ID.String(),
+				Date:     e.Challenge.DailyDate.UTC().Format(feedDateLayout),
+				OptionA:  e.Challenge.OptionA,
+				OptionB:  e.Challenge.OptionB,
+				Category: e.Challenge.Category,
+				URL:      e.Permalink,
+			},
+		}
+		if e.Challenge.Category != "" {
+			item.Tags = []string{e.Challenge.Category}
+		}
+		if e.Previous != nil {
+			percentA, percentB := votePercentages(e.Previous.VotesA, e.Previous.VotesB)
+			total := e.Previous.VotesA + e.Previous.VotesB
+			item.Yesterday = &dto.JSONFeedChallenge{
+				ID:         e.Previous.ID.String(),
+				Date:       e.Previous.DailyDate.UTC().Format(feedDateLayout),
+				OptionA:    e.Previous.OptionA,
+				OptionB:    e.Previous.OptionB,
+				Category:   e.Previous.Category,
+				PercentA:   &percentA,
+				PercentB:   &percentB,
+				TotalVotes: &total,
+				URL:        s.DailyPermalink(e.Previous.DailyDate),
+			}
+		}
+		jf.Items = append(jf.Items, item)
+	}
+
+	return json.Marshal(jf)
+}
+
+func feedEntryTitle(ch *models.Challenge) string {
+	return fmt.Sprintf("Would you rather %s or %s?", lowerFirst(ch.OptionA), lowerFirst(ch.OptionB))
+}
+
+func feedEntrySummary(e *DailyFeedEntry) string {
+	summary := fmt.Sprintf("Today's question: %s — or — %s.", e.Challenge.OptionA, e.Challenge.OptionB)
+	if e.Previous == nil {
+		return summary
+	}
+
+	percentA, percentB := votePercentages(e.Previous.VotesA, e.Previous.VotesB)
+	return fmt.Sprintf("%s Yesterday (%d votes): %d%% would rather %s, %d%% would rather %s.",
+		summary,
+		e.Previous.VotesA+e.Previous.VotesB,
+		percentA, lowerFirst(e.Previous.OptionA),
+		percentB, lowerFirst(e.Previous.OptionB),
+	)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	b := []byte(s)
+	if b[0] >= 'A' && b[0] <= 'Z' {
+		b[0] += 'a' - 'A'
+	}
+	return string(b)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
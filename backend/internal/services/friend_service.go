@@ -0,0 +1,631 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSelfFriend            = errors.New("you can't add yourself as a friend")
+	ErrAlreadyFriends        = errors.New("you are already friends")
+	ErrFriendRequestExists   = errors.New("friend request already sent")
+	ErrFriendRequestNotFound = errors.New("friend request not found")
+	ErrFriendNotFound        = errors.New("friend not found")
+	ErrFriendLimit           = errors.New("friend limit reached")
+	ErrInvalidFriendCode     = errors.New("invalid friend code")
+	ErrSearchQueryTooShort   = errors.New("search query must be at least 2 characters")
+)
+
+const (
+	friendStatusPending  = "pending"
+	friendStatusAccepted = "accepted"
+	friendStatusDeclined = "declined"
+
+	// Unambiguous upper-case characters; codes are read aloud and typed
+	friendCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+	friendCodeLength   = 8
+
+	maxFriends             = 500
+	maxPendingOutgoing     = 50
+	maxFriendSearchResults = 20
+	minFriendSearchQuery   = 2
+	maxFriendPicksListed   = 20 // Per challenge; counts still cover every friend
+
+	// A declined request can't be re-sent by the same user for this long
+	friendDeclineCooldown = 7 * 24 * time.Hour
+)
+
+// FriendService manages friend requests, friend lists and user lookup.
+// Blocks in either direction end a friendship (see ModerationService.
+// BlockUser) and hide the two users from each other here.
+type FriendService struct {
+	db          *gorm.DB
+	userService *UserService
+	notifier    Notifier
+}
+
+func NewFriendService(db *gorm.DB, userService *UserService, notifier Notifier) *FriendService {
+	return &FriendService{db: db, userService: userService, notifier: notifier}
+}
+
+// FriendCode returns the user's friend code, allocating one on first use.
+func (s *FriendService) FriendCode(userID uuid.UUID) (*dto.FriendCodeResponse, error) {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.FriendCode != nil {
+		return friendCodeResponse(*user.FriendCode), nil
+	}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := newFriendCode()
+		if err != nil {
+			return nil, err
+		}
+		res := s.db.Model(&models.User{}).
+			Where("id = ? AND friend_code IS NULL", userID).
+			Update("friend_code", code)
+		if res.Error != nil {
+			if isUniqueViolation(res.Error) {
+				continue
+			}
+			return nil, res.Error
+		}
+		if res.RowsAffected == 0 {
+			// A concurrent request allocated one first
+			if err := s.db.First(&user, "id = ?", userID).Error; err != nil || user.FriendCode == nil {
+				return nil, ErrUserNotFound
+			}
+			return friendCodeResponse(*user.FriendCode), nil
+		}
+		return friendCodeResponse(code), nil
+	}
+	return nil, errors.New("failed to allocate friend code")
+}
+
+// LookupCode resolves a friend code to the user it belongs to.
+func (s *FriendService) LookupCode(viewerID uuid.UUID, code string) (*dto.FriendUserResponse, error) {
+	user, err := s.findByCode(viewerID, code)
+	if err != nil {
+		return nil, err
+	}
+	results := s.toFriendUsers(viewerID, []models.User{*user})
+	return &results[0], nil
+}
+
+// Search finds users whose display name starts with query, or whose friend
+// code matches it exactly.
+func (s *FriendService) Search(viewerID uuid.UUID, query string) ([]dto.FriendUserResponse, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if len([]rune(query)) < minFriendSearchQuery {
+		return nil, ErrSearchQueryTooShort
+	}
+
+	pattern := escapeLike(strings.ToLower(query)) + "%"
+	var users []models.User
+	if err := s.visibleUsers(viewerID).
+		Where("display_name_key LIKE ? ESCAPE '\\' OR friend_code = ?", pattern, strings.ToUpper(query)).
+		Order("display_name_key").
+		Limit(maxFriendSearchResults).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return s.toFriendUsers(viewerID, users), nil
+}
+
+// SendRequest sends a friend request to a user picked by ID or friend code.
+// If that user already asked the sender, the two become friends instead.
+func (s *FriendService) SendRequest(userID uuid.UUID, req *dto.SendFriendRequestRequest) (*dto.FriendRequestResponse, error) {
+	var target *models.User
+	switch {
+	case req.FriendCode != "":
+		user, err := s.findByCode(userID, req.FriendCode)
+		if err != nil {
+			return nil, err
+		}
+		target = user
+	case req.UserID != nil:
+		var user models.User
+		if err := s.visibleUsers(userID).First(&user, "id = ?", *req.UserID).Error; err != nil {
+			if *req.UserID == userID {
+				return nil, ErrSelfFriend
+			}
+			return nil, ErrUserNotFound
+		}
+		target = &user
+	default:
+		return nil, ErrUserNotFound
+	}
+
+	var friendship models.Friendship
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		pairKey := models.FriendPairKey(userID, target.ID)
+		var existing models.Friendship
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("pair_key = ?", pairKey).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err == nil {
+			switch {
+			case existing.Status == friendStatusAccepted:
+				return ErrAlreadyFriends
+			case existing.Status == friendStatusPending && existing.RequesterID == userID:
+				return ErrFriendRequestExists
+			case existing.Status == friendStatusPending:
+				// They asked first: accept their request
+				if err := s.checkFriendLimit(tx, userID); err != nil {
+					return err
+				}
+				now := time.Now()
+				existing.Status = friendStatusAccepted
+				existing.RespondedAt = &now
+				friendship = existing
+				return tx.Save(&existing).Error
+			case existing.RequesterID == userID && existing.RespondedAt != nil &&
+				time.Since(*existing.RespondedAt) < friendDeclineCooldown:
+				// Don't tell the sender they were declined
+				return ErrFriendRequestExists
+			}
+		}
+
+		if err := s.checkFriendLimit(tx, userID); err != nil {
+			return err
+		}
+		var pending int64
+		tx.Model(&models.Friendship{}).
+			Where("requester_id = ? AND status = ?", userID, friendStatusPending).
+			Count(&pending)
+		if pending >= maxPendingOutgoing {
+			return ErrFriendLimit
+		}
+
+		// A declined row is reused as the new request
+		friendship = models.Friendship{
+			ID:          uuid.New(),
+			RequesterID: userID,
+			AddresseeID: target.ID,
+			PairKey:     pairKey,
+			Status:      friendStatusPending,
+		}
+		if existing.ID != uuid.Nil {
+			if err := tx.Delete(&existing).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&friendship).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrFriendRequestExists
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sender := s.displayNameOf(userID)
+	if friendship.Status == friendStatusAccepted {
+		s.notify(target.ID, Notification{
+			Type:  "friend_accepted",
+			Title: "New friend",
+			Body:  fmt.Sprintf("You and %s are now friends.", sender),
+			Data:  map[string]string{"user_id": userID.String()},
+		})
+	} else {
+		s.notify(target.ID, Notification{
+			Type:  "friend_request",
+			Title: "Friend request",
+			Body:  fmt.Sprintf("%s wants to be friends.", sender),
+			Data:  map[string]string{"request_id": friendship.ID.String(), "user_id": userID.String()},
+		})
+	}
+
+	resp := s.toRequestResponse(userID, &friendship, target)
+	return &resp, nil
+}
+
+// AcceptRequest accepts a pending request addressed to the user.
+func (s *FriendService) AcceptRequest(userID, requestID uuid.UUID) (*dto.FriendResponse, error) {
+	var friendship models.Friendship
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND addressee_id = ? AND status = ?", requestID, userID, friendStatusPending).
+			First(&friendship).Error; err != nil {
+			return ErrFriendRequestNotFound
+		}
+		if err := s.checkFriendLimit(tx, userID); err != nil {
+			return err
+		}
+		now := time.Now()
+		friendship.Status = friendStatusAccepted
+		friendship.RespondedAt = &now
+		return tx.Save(&friendship).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var requester models.User
+	if err := s.visibleUsers(userID).First(&requester, "id = ?", friendship.RequesterID).Error; err != nil {
+		return nil, ErrFriendRequestNotFound
+	}
+
+	s.notify(requester.ID, Notification{
+		Type:  "friend_accepted",
+		Title: "Friend request accepted",
+		Body:  fmt.Sprintf("%s accepted your friend request.", s.displayNameOf(userID)),
+		Data:  map[string]string{"user_id": userID.String()},
+	})
+
+	users := s.toFriendUsers(userID, []models.User{requester})
+	return &dto.FriendResponse{User: users[0], Since: *friendship.RespondedAt}, nil
+}
+
+// DeclineRequest declines a pending request addressed to the user. The
+// sender isn't told and can't re-send it for a while.
+func (s *FriendService) DeclineRequest(userID, requestID uuid.UUID) error {
+	res := s.db.Model(&models.Friendship{}).
+		Where("id = ? AND addressee_id = ? AND status = ?", requestID, userID, friendStatusPending).
+		Updates(map[string]interface{}{"status": friendStatusDeclined, "responded_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrFriendRequestNotFound
+	}
+	return nil
+}
+
+// CancelRequest withdraws a pending request the user sent.
+func (s *FriendService) CancelRequest(userID, requestID uuid.UUID) error {
+	res := s.db.Where("id = ? AND requester_id = ? AND status = ?", requestID, userID, friendStatusPending).
+		Delete(&models.Friendship{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrFriendRequestNotFound
+	}
+	return nil
+}
+
+// RemoveFriend ends a friendship.
+func (s *FriendService) RemoveFriend(userID, friendID uuid.UUID) error {
+	res := s.db.Where("pair_key = ? AND status = ?", models.FriendPairKey(userID, friendID), friendStatusAccepted).
+		Delete(&models.Friendship{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrFriendNotFound
+	}
+	return nil
+}
+
+// ListFriends returns the user's friends, most recent first.
+func (s *FriendService) ListFriends(userID uuid.UUID) ([]dto.FriendResponse, error) {
+	var friendships []models.Friendship
+	if err := s.db.Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, friendStatusAccepted).
+		Order("responded_at DESC").
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+
+	users, err := s.loadUsers(userID, friendships)
+	if err != nil {
+		return nil, err
+	}
+
+	friends := []dto.FriendResponse{}
+	for _, f := range friendships {
+		user, ok := users[otherUser(&f, userID)]
+		if !ok {
+			continue
+		}
+		since := f.CreatedAt
+		if f.RespondedAt != nil {
+			since = *f.RespondedAt
+		}
+		friends = append(friends, dto.FriendResponse{
+			User:  s.toFriendUser(&user, friendStatusOf(userID, &f)),
+			Since: since,
+		})
+	}
+	return friends, nil
+}
+
+// ListRequests returns the user's pending incoming and outgoing requests.
+// Declined requests stay listed as pending for their sender.
+func (s *FriendService) ListRequests(userID uuid.UUID) (*dto.FriendRequestsResponse, error) {
+	var friendships []models.Friendship
+	if err := s.db.Where("(addressee_id = ? AND status = ?) OR (requester_id = ? AND status IN ?)",
+		userID, friendStatusPending, userID, []string{friendStatusPending, friendStatusDeclined}).
+		Order("created_at DESC").
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+
+	users, err := s.loadUsers(userID, friendships)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.FriendRequestsResponse{
+		Incoming: []dto.FriendRequestResponse{},
+		Outgoing: []dto.FriendRequestResponse{},
+	}
+	for i := range friendships {
+		f := &friendships[i]
+		user, ok := users[otherUser(f, userID)]
+		if !ok {
+			continue
+		}
+		r := s.toRequestResponse(userID, f, &user)
+		if r.Direction == "incoming" {
+			resp.Incoming = append(resp.Incoming, r)
+		} else {
+			resp.Outgoing = append(resp.Outgoing, r)
+		}
+	}
+	return resp, nil
+}
+
+// FriendIDs returns the IDs of the user's accepted friends.
+func (s *FriendService) FriendIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	var friendships []models.Friendship
+	if err := s.db.Select("requester_id", "addressee_id").
+		Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, friendStatusAccepted).
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(friendships))
+	for i := range friendships {
+		ids[i] = otherUser(&friendships[i], userID)
+	}
+	return ids, nil
+}
+
+// friendPickRow is one row of the FriendPicks query.
+type friendPickRow struct {
+	ChallengeID uuid.UUID
+	UserID      uuid.UUID
+	Choice      string
+	DisplayName string
+	AvatarKey   string
+}
+
+// FriendPicks returns how the user's friends voted on each of the given
+// challenges, in a single query. Friends who haven't opted in with
+// ShowVotesToFriends are left out; challenges no visible friend voted on
+// are absent from the map.
+func (s *FriendService) FriendPicks(userID uuid.UUID, challengeIDs []uuid.UUID) (map[uuid.UUID]*dto.FriendPicksResponse, error) {
+	picks := map[uuid.UUID]*dto.FriendPicksResponse{}
+	if userID == uuid.Nil || len(challengeIDs) == 0 {
+		return picks, nil
+	}
+
+	var rows []friendPickRow
+	if err := s.db.Table("votes").
+		Select("votes.challenge_id, votes.user_id, votes.choice, users.display_name, users.avatar_key").
+		Joins("JOIN friendships f ON f.status = ? AND ((f.requester_id = ? AND f.addressee_id = votes.user_id) OR (f.addressee_id = ? AND f.requester_id = votes.user_id))",
+			friendStatusAccepted, userID, userID).
+		Joins("JOIN users ON users.id = votes.user_id AND users.deleted_at IS NULL AND users.deletion_requested_at IS NULL AND users.show_votes_to_friends").
+		Where("votes.challenge_id IN ? AND votes.deleted_at IS NULL", challengeIDs).
+		Order("votes.created_at DESC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, r := range rows {
+		p, ok := picks[r.ChallengeID]
+		if !ok {
+			p = &dto.FriendPicksResponse{Friends: []dto.FriendPick{}}
+			picks[r.ChallengeID] = p
+		}
+		if r.Choice == "A" {
+			p.CountA++
+		} else {
+			p.CountB++
+		}
+		if len(p.Friends) < maxFriendPicksListed {
+			p.Friends = append(p.Friends, dto.FriendPick{
+				UserID:      r.UserID,
+				DisplayName: r.DisplayName,
+				AvatarURL:   s.userService.AvatarURL(&models.User{AvatarKey: r.AvatarKey}),
+				Choice:      r.Choice,
+			})
+		}
+	}
+	return picks, nil
+}
+
+// visibleUsers scopes a user query to accounts the viewer may see: not
+// themselves, not pending deletion and not blocked in either direction.
+func (s *FriendService) visibleUsers(viewerID uuid.UUID) *gorm.DB {
+	return s.db.Model(&models.User{}).
+		Where("users.id <> ?", viewerID).
+		Where("users.deletion_requested_at IS NULL").
+		Where(`NOT EXISTS (SELECT 1 FROM blocks b WHERE
+			(b.blocker_id = ? AND b.blocked_id = users.id) OR
+			(b.blocker_id = users.id AND b.blocked_id = ?))`, viewerID, viewerID)
+}
+
+func (s *FriendService) findByCode(viewerID uuid.UUID, code string) (*models.User, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != friendCodeLength || strings.Trim(code, friendCodeAlphabet) != "" {
+		return nil, ErrInvalidFriendCode
+	}
+
+	var user models.User
+	if err := s.visibleUsers(viewerID).Where("friend_code = ?", code).First(&user).Error; err != nil {
+		var own int64
+		s.db.Model(&models.User{}).Where("id = ? AND friend_code = ?", viewerID, code).Count(&own)
+		if own > 0 {
+			return nil, ErrSelfFriend
+		}
+		return nil, ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// loadUsers fetches the visible counterparts of the given friendships.
+func (s *FriendService) loadUsers(userID uuid.UUID, friendships []models.Friendship) (map[uuid.UUID]models.User, error) {
+	users := map[uuid.UUID]models.User{}
+	if len(friendships) == 0 {
+		return users, nil
+	}
+
+	ids := make([]uuid.UUID, len(friendships))
+	for i := range friendships {
+		ids[i] = otherUser(&friendships[i], userID)
+	}
+	var rows []models.User
+	if err := s.visibleUsers(userID).Where("users.id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range rows {
+		users[u.ID] = u
+	}
+	return users, nil
+}
+
+func (s *FriendService) checkFriendLimit(tx *gorm.DB, userID uuid.UUID) error {
+	var count int64
+	tx.Model(&models.Friendship{}).
+		Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, friendStatusAccepted).
+		Count(&count)
+	if count >= maxFriends {
+		return ErrFriendLimit
+	}
+	return nil
+}
+
+// toFriendUsers maps users to responses with the viewer's relationship to
+// each, looked up in one query.
+func (s *FriendService) toFriendUsers(viewerID uuid.UUID, users []models.User) []dto.FriendUserResponse {
+	results := make([]dto.FriendUserResponse, 0, len(users))
+	if len(users) == 0 {
+		return results
+	}
+
+	keys := make([]string, len(users))
+	for i, u := range users {
+		keys[i] = models.FriendPairKey(viewerID, u.ID)
+	}
+	var friendships []models.Friendship
+	s.db.Where("pair_key IN ?", keys).Find(&friendships)
+	byUser := make(map[uuid.UUID]*models.Friendship, len(friendships))
+	for i := range friendships {
+		byUser[otherUser(&friendships[i], viewerID)] = &friendships[i]
+	}
+
+	for i := range users {
+		results = append(results, s.toFriendUser(&users[i], friendStatusOf(viewerID, byUser[users[i].ID])))
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Status == "friends" && results[j].Status != "friends"
+	})
+	return results
+}
+
+func (s *FriendService) toFriendUser(user *models.User, status string) dto.FriendUserResponse {
+	return dto.FriendUserResponse{
+		ID:          user.ID,
+		DisplayName: user.DisplayName,
+		AvatarURL:   s.userService.AvatarURL(user),
+		Status:      status,
+	}
+}
+
+func (s *FriendService) toRequestResponse(viewerID uuid.UUID, f *models.Friendship, other *models.User) dto.FriendRequestResponse {
+	direction := "incoming"
+	if f.RequesterID == viewerID {
+		direction = "outgoing"
+	}
+	status := f.Status
+	if status == friendStatusDeclined {
+		status = friendStatusPending
+	}
+	return dto.FriendRequestResponse{
+		ID:        f.ID,
+		User:      s.toFriendUser(other, friendStatusOf(viewerID, f)),
+		Direction: direction,
+		Status:    status,
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+func (s *FriendService) displayNameOf(userID uuid.UUID) string {
+	var user models.User
+	if err := s.db.Select("display_name").First(&user, "id = ?", userID).Error; err != nil || user.DisplayName == "" {
+		return "Someone"
+	}
+	return user.DisplayName
+}
+
+func (s *FriendService) notify(userID uuid.UUID, n Notification) {
+	if err := s.notifier.Notify(userID, n); err != nil {
+		log.Printf("Failed to send %s notification to user %s: %v", n.Type, userID, err)
+	}
+}
+
+// friendStatusOf describes a friendship from the viewer's side. A declined
+// request still looks pending to its sender.
+func friendStatusOf(viewerID uuid.UUID, f *models.Friendship) string {
+	switch {
+	case f == nil:
+		return "none"
+	case f.Status == friendStatusAccepted:
+		return "friends"
+	case f.RequesterID == viewerID:
+		return "outgoing"
+	case f.Status == friendStatusPending:
+		return "incoming"
+	}
+	return "none"
+}
+
+func otherUser(f *models.Friendship, userID uuid.UUID) uuid.UUID {
+	if f.RequesterID == userID {
+		return f.AddresseeID
+	}
+	return f.RequesterID
+}
+
+func friendCodeResponse(code string) *dto.FriendCodeResponse {
+	return &dto.FriendCodeResponse{
+		Code: code,
+		Link: fmt.Sprintf("%s://add-friend?code=%s", appScheme, code),
+	}
+}
+
+func newFriendCode() (string, error) {
+	buf := make([]byte, friendCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = friendCodeAlphabet[int(b)%len(friendCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// escapeLike escapes LIKE wildcards in user input.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
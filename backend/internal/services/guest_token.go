@@ -0,0 +1,83 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidGuestToken = errors.New("invalid guest token")
+	ErrTooManyGuests     = errors.New("too many guest sessions from this network, please sign up")
+)
+
+const (
+	guestTokenType = "guest"
+	// guestIDPrefix marks server-issued guest IDs. Only a signed token
+	// proves one, so raw IDs with the prefix are never accepted.
+	guestIDPrefix = "g_"
+)
+
+// IsRawGuestID reports whether id may be used as an unsigned legacy
+// device ID, i.e. it can't impersonate a server-issued guest.
+func IsRawGuestID(id string) bool {
+	return id != "" && len(id) <= 64 && !strings.HasPrefix(id, guestIDPrefix)
+}
+
+// guestSigningKey derives the guest token key from JWT_SECRET. Using a
+// separate key means a guest token can never pass as a user access token.
+func guestSigningKey(cfg *config.Config) []byte {
+	mac := hmac.New(sha256.New, []byte(cfg.JWTSecret))
+	mac.Write([]byte("guest-token"))
+	return mac.Sum(nil)
+}
+
+// NewGuestToken signs a guest token for guestID.
+func NewGuestToken(cfg *config.Config, guestID string) (string, time.Time, error) {
+	now := time.Now()
+	expires := now.Add(cfg.GuestTokenExpiry)
+	claims := jwt.MapClaims{
+		"typ": guestTokenType,
+		"gid": guestID,
+		"iat": now.Unix(),
+		"exp": expires.Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(guestSigningKey(cfg))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign guest token: %w", err)
+	}
+	return token, expires, nil
+}
+
+// ParseGuestToken verifies a guest token and returns its guest ID. With
+// allowExpired, an expired but authentic token is accepted (for renewal).
+func ParseGuestToken(cfg *config.Config, tokenStr string, allowExpired bool) (string, error) {
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})}
+	if allowExpired {
+		opts = append(opts, jwt.WithoutClaimsValidation())
+	}
+
+	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
+		return guestSigningKey(cfg), nil
+	}, opts...)
+	if err != nil || !token.Valid {
+		return "", ErrInvalidGuestToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != guestTokenType {
+		return "", ErrInvalidGuestToken
+	}
+	guestID, _ := claims["gid"].(string)
+	if guestID == "" {
+		return "", ErrInvalidGuestToken
+	}
+	return guestID, nil
+}
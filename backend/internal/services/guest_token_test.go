@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func testGuestConfig() *config.Config {
+	return &config.Config{
+		JWTSecret:        "test-secret-at-least-32-characters-long",
+		JWTAccessExpiry:  15 * time.Minute,
+		GuestTokenExpiry: time.Hour,
+	}
+}
+
+func signGuestClaims(t *testing.T, key []byte, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return token
+}
+
+func TestParseGuestTokenRoundTrip(t *testing.T) {
+	cfg := testGuestConfig()
+	token, _, err := NewGuestToken(cfg, "g_abc")
+	if err != nil {
+		t.Fatalf("NewGuestToken: %v", err)
+	}
+
+	for _, allowExpired := range []bool{false, true} {
+		guestID, err := ParseGuestToken(cfg, token, allowExpired)
+		if err != nil || guestID != "g_abc" {
+			t.Errorf("ParseGuestToken(allowExpired=%v) = %q, %v; want g_abc", allowExpired, guestID, err)
+		}
+	}
+}
+
+func TestParseGuestTokenRejectsOtherTokens(t *testing.T) {
+	cfg := testGuestConfig()
+	guestKey := guestSigningKey(cfg)
+	exp := time.Now().Add(time.Hour).Unix()
+
+	accessToken, err := (&AuthService{cfg: cfg}).generateAccessToken(&models.User{ID: uuid.New(), Email: "a@example.com"})
+	if err != nil {
+		t.Fatalf("generateAccessToken: %v", err)
+	}
+	otherCfg := testGuestConfig()
+	otherCfg.JWTSecret = "another-secret-at-least-32-characters"
+	foreign, _, _ := NewGuestToken(otherCfg, "g_abc")
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"typ": guestTokenType, "gid": "g_abc", "exp": exp,
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"user access token", accessToken},
+		{"access token claims under the guest key", signGuestClaims(t, guestKey, jwt.MapClaims{"sub": uuid.NewString(), "gid": "g_abc", "exp": exp})},
+		{"wrong typ under the guest key", signGuestClaims(t, guestKey, jwt.MapClaims{"typ": "access", "gid": "g_abc", "exp": exp})},
+		{"guest claims under JWT_SECRET", signGuestClaims(t, []byte(cfg.JWTSecret), jwt.MapClaims{"typ": guestTokenType, "gid": "g_abc", "exp": exp})},
+		{"missing guest ID", signGuestClaims(t, guestKey, jwt.MapClaims{"typ": guestTokenType, "exp": exp})},
+		{"signed with another secret", foreign},
+		{"alg none", unsigned},
+		{"garbage", "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, allowExpired := range []bool{false, true} {
+				if _, err := ParseGuestToken(cfg, tt.token, allowExpired); !errors.Is(err, ErrInvalidGuestToken) {
+					t.Errorf("ParseGuestToken(allowExpired=%v) error = %v, want ErrInvalidGuestToken", allowExpired, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseGuestTokenExpiry(t *testing.T) {
+	cfg := testGuestConfig()
+	expired := signGuestClaims(t, guestSigningKey(cfg), jwt.MapClaims{
+		"typ": guestTokenType, "gid": "g_old", "exp": time.Now().Add(-time.Minute).Unix(),
+	})
+
+	if _, err := ParseGuestToken(cfg, expired, false); !errors.Is(err, ErrInvalidGuestToken) {
+		t.Errorf("expired token accepted for requests: %v", err)
+	}
+	if guestID, err := ParseGuestToken(cfg, expired, true); err != nil || guestID != "g_old" {
+		t.Errorf("expired token for renewal = %q, %v; want g_old", guestID, err)
+	}
+}
+
+func TestIsRawGuestID(t *testing.T) {
+	tests := map[string]bool{
+		"3f2a9c1e-device":       true,
+		"":                      false,
+		"g_0123456789abcdef":    false,
+		strings.Repeat("a", 64): true,
+		strings.Repeat("a", 65): false,
+	}
+	for id, want := range tests {
+		if got := IsRawGuestID(id); got != want {
+			t.Errorf("IsRawGuestID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
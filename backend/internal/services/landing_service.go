@@ -0,0 +1,342 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrLandingNotFound = errors.New("page not found")
+
+const (
+	appScheme         = "wouldyou"
+	oembedMaxWidth    = 600
+	oembedEmbedHeight = 420
+)
+
+// LandingPage is everything a share or daily landing page renders: Open
+// Graph/Twitter meta, the live split and the app deep link.
+type LandingPage struct {
+	Title        string
+	Description  string
+	URL          string // Canonical page URL
+	ImageURL     string
+	ImageWidth   int
+	ImageHeight  int
+	DeepLink     template.URL // Custom scheme, so it must bypass html/template's URL filter
+	OEmbedURL    string
+	OptionA      string
+	OptionB      string
+	PercentA     int
+	PercentB     int
+	TotalVotes   int
+	SharerChoice string
+	AppStoreURL  string
+	PlayStoreURL string
+	AppleAppID   string
+	Embed        bool      // Compact layout for oEmbed iframes
+	LastModified time.Time // Sent as Last-Modified; zero when unknown
+}
+
+// LandingService builds the public HTML pages behind share links and daily
+// permalinks, plus their oEmbed descriptions.
+type LandingService struct {
+	db               *gorm.DB
+	cfg              *config.Config
+	challengeService *ChallengeService
+	feedService      *FeedService
+	shareService     *ShareService
+	renderer         *ShareCardRenderer
+}
+
+func NewLandingService(db *gorm.DB, cfg *config.Config, cs *ChallengeService, fs *FeedService, ss *ShareService, renderer *ShareCardRenderer) *LandingService {
+	return &LandingService{
+		db:               db,
+		cfg:              cfg,
+		challengeService: cs,
+		feedService:      fs,
+		shareService:     ss,
+		renderer:         renderer,
+	}
+}
+
+// SharePage returns the landing page for a share code. Opening it counts as
+// a share open attributed to via.
+func (s *LandingService) SharePage(code, via string) (*LandingPage, error) {
+	shared, err := s.shareService.GetSharedChallenge(code, via)
+	if err != nil {
+		if errors.Is(err, ErrShareNotFound) {
+			return nil, ErrLandingNotFound
+		}
+		return nil, err
+	}
+
+	page := s.newPage(shared)
+	page.ImageURL = fmt.Sprintf("%s/api/share/%s/card.png?format=%s", s.cfg.PublicBaseURL, url.PathEscape(code), ShareCardLink)
+	page.DeepLink = template.URL(fmt.Sprintf("%s://share/%s", appScheme, url.PathEscape(code)))
+	if pick := pickedOption(shared); pick != "" {
+		page.Description = fmt.Sprintf("I'd rather %s. %s", lowerFirst(pick), page.Description)
+	}
+	return page, nil
+}
+
+// DailyPage returns the landing page for the daily challenge of a date
+// (YYYY-MM-DD). Today's daily is created if it doesn't exist yet.
+func (s *LandingService) DailyPage(date string) (*LandingPage, error) {
+	ch, err := s.dailyChallenge(date)
+	if err != nil {
+		return nil, err
+	}
+
+	percentA, percentB := votePercentages(ch.VotesA, ch.VotesB)
+	page := s.newPage(&dto.SharedChallengeResponse{
+		Code:       "daily-" + date,
+		URL:        s.feedService.DailyPermalink(ch.DailyDate),
+		OptionA:    ch.OptionA,
+		OptionB:    ch.OptionB,
+		PercentA:   percentA,
+		PercentB:   percentB,
+		TotalVotes: ch.VotesA + ch.VotesB,
+	})
+	page.Title = "Daily Would You Rather: " + page.Title
+	page.ImageURL = fmt.Sprintf("%s/daily/%s/card.png", s.cfg.PublicBaseURL, date)
+	page.DeepLink = template.URL(fmt.Sprintf("%s://daily/%s", appScheme, date))
+	page.LastModified = ch.UpdatedAt
+	return page, nil
+}
+
+// DailyCard renders the link-preview image for a daily permalink.
+func (s *LandingService) DailyCard(date string) (*ShareCard, error) {
+	ch, err := s.dailyChallenge(date)
+	if err != nil {
+		return nil, err
+	}
+
+	percentA, percentB := votePercentages(ch.VotesA, ch.VotesB)
+	return s.renderer.Render(&dto.SharedChallengeResponse{
+		Code:       "daily-" + date,
+		URL:        s.feedService.DailyPermalink(ch.DailyDate),
+		OptionA:    ch.OptionA,
+		OptionB:    ch.OptionB,
+		PercentA:   percentA,
+		PercentB:   percentB,
+		TotalVotes: ch.VotesA + ch.VotesB,
+	}, ShareCardLink)
+}
+
+func (s *LandingService) dailyChallenge(date string) (*models.Challenge, error) {
+	day, err := time.Parse(feedDateLayout, date)
+	if err != nil {
+		return nil, ErrLandingNotFound
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if day.After(today) {
+		return nil, ErrLandingNotFound
+	}
+	if day.Equal(today) {
+		return s.challengeService.GetDailyChallenge()
+	}
+
+	var ch models.Challenge
+	if err := s.db.Where("is_daily = ? AND daily_date = ?", true, day).First(&ch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLandingNotFound
+		}
+		return nil, err
+	}
+	return &ch, nil
+}
+
+func (s *LandingService) newPage(shared *dto.SharedChallengeResponse) *LandingPage {
+	description := fmt.Sprintf("%d%% would rather %s, %d%% would rather %s. What would you pick?",
+		shared.PercentA, lowerFirst(shared.OptionA), shared.PercentB, lowerFirst(shared.OptionB))
+	if shared.TotalVotes == 0 {
+		description = "Be the first to vote. What would you pick?"
+	}
+
+	size := shareCardSizes[ShareCardLink]
+	return &LandingPage{
+		Title:        fmt.Sprintf("Would you rather %s or %s?", lowerFirst(shared.OptionA), lowerFirst(shared.OptionB)),
+		Description:  description,
+		URL:          shared.URL,
+		ImageWidth:   size.X,
+		ImageHeight:  size.Y,
+		OEmbedURL:    fmt.Sprintf("%s/oembed?format=json&url=%s", s.cfg.PublicBaseURL, url.QueryEscape(shared.URL)),
+		OptionA:      shared.OptionA,
+		OptionB:      shared.OptionB,
+		PercentA:     shared.PercentA,
+		PercentB:     shared.PercentB,
+		TotalVotes:   shared.TotalVotes,
+		SharerChoice: shared.SharerChoice,
+		AppStoreURL:  s.cfg.AppStoreURL,
+		PlayStoreURL: s.cfg.PlayStoreURL,
+		AppleAppID:   s.cfg.AppleAppID,
+	}
+}
+
+// OEmbed describes a share or daily URL on this site as an embeddable
+// iframe, along with the described page's last modification time.
+// maxWidth/maxHeight of 0 mean no limit.
+func (s *LandingService) OEmbed(rawURL string, maxWidth, maxHeight int) (*dto.OEmbedResponse, time.Time, error) {
+	if !strings.HasPrefix(rawURL, s.cfg.PublicBaseURL+"/") {
+		return nil, time.Time{}, ErrLandingNotFound
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, time.Time{}, ErrLandingNotFound
+	}
+
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) != 2 {
+		return nil, time.Time{}, ErrLandingNotFound
+	}
+
+	var page *LandingPage
+	switch parts[0] {
+	case "s":
+		shared, err := s.shareService.LookupSharedChallenge(parts[1])
+		if err != nil {
+			if errors.Is(err, ErrShareNotFound) {
+				return nil, time.Time{}, ErrLandingNotFound
+			}
+			return nil, time.Time{}, err
+		}
+		page = s.newPage(shared)
+		page.ImageURL = fmt.Sprintf("%s/api/share/%s/card.png?format=%s", s.cfg.PublicBaseURL, url.PathEscape(parts[1]), ShareCardLink)
+	case "daily":
+		page, err = s.DailyPage(parts[1])
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+	default:
+		return nil, time.Time{}, ErrLandingNotFound
+	}
+
+	width, height := oembedMaxWidth, oembedEmbedHeight
+	if maxWidth > 0 && maxWidth < width {
+		width = maxWidth
+	}
+	if maxHeight > 0 && maxHeight < height {
+		height = maxHeight
+	}
+
+	embedURL := page.URL + "?embed=1"
+	return &dto.OEmbedResponse{
+		Type:         "rich",
+		Version:      "1.0",
+		Title:        page.Title,
+		ProviderName: "WouldYou",
+		ProviderURL:  s.cfg.PublicBaseURL,
+		HTML: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" frameborder="0" scrolling="no" title="%s" style="border:0;border-radius:16px;max-width:100%%"></iframe>`,
+			template.HTMLEscapeString(embedURL), width, height, template.HTMLEscapeString(page.Title)),
+		Width:           width,
+		Height:          height,
+		ThumbnailURL:    page.ImageURL,
+		ThumbnailWidth:  page.ImageWidth,
+		ThumbnailHeight: page.ImageHeight,
+		CacheAge:        300,
+	}, page.LastModified, nil
+}
+
+// RenderHTML renders a landing page.
+func (s *LandingService) RenderHTML(page *LandingPage) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := landingTemplate.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func pickedOption(shared *dto.SharedChallengeResponse) string {
+	switch shared.SharerChoice {
+	case "A":
+		return shared.OptionA
+	case "B":
+		return shared.OptionB
+	}
+	return ""
+}
+
+var landingTemplate = template.Must(template.New("landing").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width,initial-scale=1">
+<title>{{.Title}} - WouldYou</title>
+<meta name="description" content="{{.Description}}">
+<link rel="canonical" href="{{.URL}}">
+<link rel="alternate" type="application/json+oembed" href="{{.OEmbedURL}}" title="{{.Title}}">
+<meta property="og:type" content="website">
+<meta property="og:site_name" content="WouldYou">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:image" content="{{.ImageURL}}">
+<meta property="og:image:type" content="image/png">
+<meta property="og:image:width" content="{{.ImageWidth}}">
+<meta property="og:image:height" content="{{.ImageHeight}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<meta name="twitter:image" content="{{.ImageURL}}">
+<meta property="al:ios:url" content="{{.DeepLink}}">
+<meta property="al:android:url" content="{{.DeepLink}}">
+{{if .AppleAppID}}<meta name="apple-itunes-app" content="app-id={{.AppleAppID}}, app-argument={{.DeepLink}}">
+{{end}}<style>
+body{margin:0;font-family:-apple-system,system-ui,sans-serif;background:#0A0A12;color:#FFFFFF;display:flex;justify-content:center}
+main{width:100%;max-width:560px;padding:{{if .Embed}}16px{{else}}40px 20px{{end}};box-sizing:border-box}
+h1{color:#FFE66D;font-size:22px;text-align:center;letter-spacing:1px;margin:0 0 20px}
+.option{background:#2A2A4A;border-radius:20px;padding:20px;margin-bottom:14px;border:3px solid transparent}
+.option.picked{border-color:#FFE66D}
+.text{font-size:20px;font-weight:700;margin-bottom:14px}
+.bar{background:#1A1A2E;border-radius:8px;height:12px;overflow:hidden}
+.fill{height:100%;border-radius:8px}
+.a .fill{background:#FF6B9D}.b .fill{background:#00D4AA}
+.meta{display:flex;justify-content:space-between;margin-top:8px;color:#B8B8D0;font-size:14px}
+.badge{display:inline-block;background:#FFE66D;color:#0A0A12;font-size:12px;font-weight:700;border-radius:10px;padding:3px 10px;margin-bottom:10px}
+.cta{display:block;text-align:center;background:#FF6B9D;color:#FFFFFF;text-decoration:none;font-weight:700;border-radius:16px;padding:16px;margin-top:20px}
+.stores{text-align:center;margin-top:14px;font-size:14px}.stores a{color:#B8B8D0;margin:0 8px}
+</style>
+</head>
+<body>
+<main>
+<h1>WOULD YOU RATHER?</h1>
+<div class="option a{{if eq .SharerChoice "A"}} picked{{end}}">
+{{if eq .SharerChoice "A"}}<span class="badge">THEIR PICK</span>{{end}}
+<div class="text">{{.OptionA}}</div>
+<div class="bar"><div class="fill" style="width:{{.PercentA}}%"></div></div>
+<div class="meta"><span>{{.PercentA}}%</span></div>
+</div>
+<div class="option b{{if eq .SharerChoice "B"}} picked{{end}}">
+{{if eq .SharerChoice "B"}}<span class="badge">THEIR PICK</span>{{end}}
+<div class="text">{{.OptionB}}</div>
+<div class="bar"><div class="fill" style="width:{{.PercentB}}%"></div></div>
+<div class="meta"><span>{{.PercentB}}%</span><span>{{.TotalVotes}} votes</span></div>
+</div>
+<a class="cta" id="open" href="{{.DeepLink}}"{{if .Embed}} target="_top"{{end}}>Vote in WouldYou</a>
+{{if not .Embed}}<div class="stores"><a href="{{.AppStoreURL}}">App Store</a><a href="{{.PlayStoreURL}}">Google Play</a></div>{{end}}
+</main>
+{{if not .Embed}}<script>
+(function () {
+  var ua = navigator.userAgent || "";
+  var store = /android/i.test(ua) ? {{.PlayStoreURL}} : /iphone|ipad|ipod/i.test(ua) ? {{.AppStoreURL}} : "";
+  if (!store) return;
+  // Try the app first; if the page is still visible afterwards, it isn't installed
+  var timer = setTimeout(function () { window.location.href = store; }, 1500);
+  document.addEventListener("visibilitychange", function () { if (document.hidden) clearTimeout(timer); });
+  window.location.href = {{.DeepLink}};
+})();
+</script>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+)
+
+// EmailMessage is a rendered email with plain-text and HTML bodies.
+type EmailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers email. MAIL_DRIVER selects the implementation: "smtp"
+// for production, "file" or "log" for development.
+type Mailer interface {
+	Send(msg *EmailMessage) error
+}
+
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for MAIL_DRIVER=smtp")
+		}
+		return &SMTPMailer{
+			addr: cfg.SMTPHost + ":" + cfg.SMTPPort,
+			host: cfg.SMTPHost,
+			user: cfg.SMTPUsername,
+			pass: cfg.SMTPPassword,
+			from: cfg.MailFrom,
+		}, nil
+	case "file":
+		if err := os.MkdirAll(cfg.MailDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create mail directory: %w", err)
+		}
+		return &FileMailer{dir: cfg.MailDir, from: cfg.MailFrom}, nil
+	case "", "log":
+		return &LogMailer{}, nil
+	}
+	return nil, fmt.Errorf("unknown MAIL_DRIVER %q", cfg.MailDriver)
+}
+
+// SMTPMailer sends through an SMTP relay, using STARTTLS when offered.
+type SMTPMailer struct {
+	addr string
+	host string
+	user string
+	pass string
+	from string
+}
+
+func (m *SMTPMailer) Send(msg *EmailMessage) error {
+	body, err := buildMIME(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.user != "" {
+		auth = smtp.PlainAuth("", m.user, m.pass, m.host)
+	}
+	if err := smtp.SendMail(m.addr, auth, envelopeAddress(m.from), []string{msg.To}, body); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// FileMailer writes each message as an .eml file under MAIL_DIR.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func (m *FileMailer) Send(msg *EmailMessage) error {
+	body, err := buildMIME(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), hex.EncodeToString(suffix))
+	return os.WriteFile(filepath.Join(m.dir, name), body, 0o644)
+}
+
+// LogMailer prints messages to the server log instead of sending them.
+type LogMailer struct{}
+
+func (LogMailer) Send(msg *EmailMessage) error {
+	log.Printf("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
+
+// buildMIME renders a multipart/alternative message.
+func buildMIME(from string, msg *EmailMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, p := range parts {
+		if p.body == "" {
+			continue
+		}
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		qp.Write([]byte(p.body))
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// envelopeAddress extracts the bare address from "Name <addr>".
+func envelopeAddress(from string) string {
+	if i := strings.LastIndex(from, "<"); i >= 0 {
+		return strings.TrimSuffix(from[i+1:], ">")
+	}
+	return from
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"log"
 	"regexp"
 	"strings"
 
@@ -26,11 +27,12 @@ var ProfanityPatterns = []*regexp.Regexp{
 }
 
 type ModerationService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	notifier Notifier
 }
 
-func NewModerationService(db *gorm.DB) *ModerationService {
-	return &ModerationService{db: db}
+func NewModerationService(db *gorm.DB, notifier Notifier) *ModerationService {
+	return &ModerationService{db: db, notifier: notifier}
 }
 
 // --- Content Filtering ---
@@ -57,9 +59,9 @@ func (s *ModerationService) SanitizeContent(text string) string {
 // --- Reports ---
 
 func (s *ModerationService) CreateReport(reporterID uuid.UUID, req *dto.CreateReportRequest) (*models.Report, error) {
-	validTypes := map[string]bool{"user": true, "post": true, "comment": true}
+	validTypes := map[string]bool{"user": true, "post": true, "comment": true, "challenge": true}
 	if !validTypes[req.ContentType] {
-		return nil, errors.New("invalid content_type: must be user, post, or comment")
+		return nil, errors.New("invalid content_type: must be user, post, comment, or challenge")
 	}
 
 	if strings.TrimSpace(req.Reason) == "" {
@@ -106,18 +108,58 @@ func (s *ModerationService) ActionReport(reportID uuid.UUID, req *dto.ActionRepo
 		return errors.New("invalid status: must be reviewed, actioned, or dismissed")
 	}
 
-	result := s.db.Model(&models.Report{}).
-		Where("id = ?", reportID).
-		Updates(map[string]interface{}{
+	var report models.Report
+	if err := s.db.First(&report, "id = ?", reportID).Error; err != nil {
+		return ErrReportNotFound
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&report).Updates(map[string]interface{}{
 			"status":     req.Status,
 			"admin_note": req.AdminNote,
-		})
+		}).Error; err != nil {
+			return err
+		}
 
-	if result.RowsAffected == 0 {
-		return ErrReportNotFound
+		// Actioned challenges are hidden everywhere, including offline packs
+		if req.Status == "actioned" && report.ContentType == "challenge" {
+			challengeID, err := uuid.Parse(report.ContentID)
+			if err != nil {
+				return nil
+			}
+			return tx.Delete(&models.Challenge{}, "id = ?", challengeID).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	return result.Error
+	s.notifyReportOutcome(&report, req.Status)
+	return nil
+}
+
+// notifyReportOutcome tells the reporter how their report was resolved.
+// "reviewed" is an interim state and stays silent.
+func (s *ModerationService) notifyReportOutcome(report *models.Report, status string) {
+	var body string
+	switch status {
+	case "actioned":
+		body = "Thanks for your report. We reviewed it and took action."
+	case "dismissed":
+		body = "Thanks for your report. We reviewed it and found no violation of our guidelines."
+	default:
+		return
+	}
+
+	if err := s.notifier.Notify(report.ReporterID, Notification{
+		Type:  "report_resolved",
+		Title: "Your report was reviewed",
+		Body:  body,
+		Data:  map[string]string{"report_id": report.ID.String(), "status": status},
+	}); err != nil {
+		log.Printf("Failed to notify reporter %s: %v", report.ReporterID, err)
+	}
 }
 
 // --- Blocking ---
@@ -138,7 +180,14 @@ func (s *ModerationService) BlockUser(blockerID, blockedID uuid.UUID) error {
 		BlockedID: blockedID,
 	}
 
-	return s.db.Create(&block).Error
+	// A block ends any friendship or pending request between the two
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&block).Error; err != nil {
+			return err
+		}
+		return tx.Where("pair_key = ?", models.FriendPairKey(blockerID, blockedID)).
+			Delete(&models.Friendship{}).Error
+	})
 }
 
 func (s *ModerationService) UnblockUser(blockerID, blockedID uuid.UUID) error {
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+)
+
+const (
+	defaultNotificationPage = 20
+	maxNotificationPage     = 50
+)
+
+// NotificationService is the Notifier the rest of the backend emits
+// through. Every notification lands in the user's inbox first and is then
+// handed to the extra delivery channels (log, push).
+type NotificationService struct {
+	db       *gorm.DB
+	ttl      time.Duration
+	channels []Notifier
+}
+
+func NewNotificationService(db *gorm.DB, cfg *config.Config, channels ...Notifier) *NotificationService {
+	return &NotificationService{db: db, ttl: cfg.NotificationTTL, channels: channels}
+}
+
+// Notify stores n in the user's inbox and fans it out to the delivery
+// channels in the background, so a slow push provider never holds up the
+// caller. Channel failures are logged; the inbox entry stands.
+func (s *NotificationService) Notify(userID uuid.UUID, n Notification) error {
+	if userID == uuid.Nil {
+		return nil
+	}
+
+	if err := s.db.Create(&models.Notification{
+		UserID:    userID,
+		Type:      n.Type,
+		Title:     n.Title,
+		Body:      n.Body,
+		Payload:   n.Data,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}).Error; err != nil {
+		return err
+	}
+
+	go s.deliver(userID, n)
+	return nil
+}
+
+func (s *NotificationService) deliver(userID uuid.UUID, n Notification) {
+	for _, ch := range s.channels {
+		if err := ch.Notify(userID, n); err != nil {
+			log.Printf("Failed to deliver %s notification to user %s: %v", n.Type, userID, err)
+		}
+	}
+}
+
+// List returns a page of the user's unexpired notifications, newest first.
+// cursor is the NextCursor of the previous page.
+func (s *NotificationService) List(userID uuid.UUID, cursor string, limit int, unreadOnly bool) (*dto.NotificationsResponse, error) {
+	if limit <= 0 || limit > maxNotificationPage {
+		limit = defaultNotificationPage
+	}
+
+	now := time.Now()
+	q := s.db.Where("user_id = ? AND expires_at > ?", userID, now)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	if cursor != "" {
+		createdAt, id, err := decodeNotificationCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		q = q.Where("(created_at < ? OR (created_at = ? AND id < ?))", createdAt, createdAt, id)
+	}
+
+	// One extra row tells us whether there is a next page
+	var rows []models.Notification
+	if err := q.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	resp := &dto.NotificationsResponse{Notifications: make([]dto.NotificationResponse, 0, len(rows))}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		resp.NextCursor = encodeNotificationCursor(last.CreatedAt, last.ID)
+	}
+	for i := range rows {
+		resp.Notifications = append(resp.Notifications, toNotificationResponse(&rows[i]))
+	}
+
+	s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND expires_at > ? AND read_at IS NULL", userID, now).
+		Count(&resp.UnreadCount)
+	return resp, nil
+}
+
+// MarkRead marks one of the user's notifications as read.
+func (s *NotificationService) MarkRead(userID, notificationID uuid.UUID) (*dto.NotificationResponse, error) {
+	var n models.Notification
+	if err := s.db.Where("id = ? AND user_id = ? AND expires_at > ?", notificationID, userID, time.Now()).
+		First(&n).Error; err != nil {
+		return nil, ErrNotificationNotFound
+	}
+
+	if n.ReadAt == nil {
+		now := time.Now()
+		if err := s.db.Model(&n).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+		n.ReadAt = &now
+	}
+
+	resp := toNotificationResponse(&n)
+	return &resp, nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and
+// returns how many were updated.
+func (s *NotificationService) MarkAllRead(userID uuid.UUID) (int64, error) {
+	res := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return res.RowsAffected, res.Error
+}
+
+// CleanupExpired deletes notifications past their expiry.
+func (s *NotificationService) CleanupExpired(ctx context.Context) error {
+	return s.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&models.Notification{}).Error
+}
+
+func toNotificationResponse(n *models.Notification) dto.NotificationResponse {
+	payload := n.Payload
+	if payload == nil {
+		payload = map[string]string{}
+	}
+	return dto.NotificationResponse{
+		ID:        n.ID,
+		Type:      n.Type,
+		Title:     n.Title,
+		Body:      n.Body,
+		Payload:   payload,
+		Read:      n.ReadAt != nil,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+		ExpiresAt: n.ExpiresAt,
+	}
+}
+
+// Cursors are the position of the last row seen, so pages stay stable
+// while new notifications arrive.
+func encodeNotificationCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeNotificationCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	ts, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	return createdAt, id, nil
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNotificationCursorRoundTrip(t *testing.T) {
+	ist := time.FixedZone("TRT", 3*60*60)
+	createdAt := time.Date(2026, 5, 1, 9, 30, 15, 123456789, ist)
+	id := uuid.New()
+
+	cursor := encodeNotificationCursor(createdAt, id)
+	gotAt, gotID, err := decodeNotificationCursor(cursor)
+	if err != nil {
+		t.Fatalf("decodeNotificationCursor: %v", err)
+	}
+	if !gotAt.Equal(createdAt) || gotID != id {
+		t.Errorf("decoded %v, %v; want %v, %v", gotAt, gotID, createdAt, id)
+	}
+	if gotAt.Location() != time.UTC {
+		t.Errorf("decoded time in %v, want UTC", gotAt.Location())
+	}
+}
+
+func TestDecodeNotificationCursorRejectsGarbage(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for _, cursor := range []string{
+		"",
+		"not base64!",
+		enc("2026-05-01T09:30:15Z"),
+		enc("yesterday|" + uuid.NewString()),
+		enc("2026-05-01T09:30:15Z|not-a-uuid"),
+	} {
+		if _, _, err := decodeNotificationCursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeNotificationCursor(%q) error = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// Notification is a user-facing message such as "your data export is ready".
+type Notification struct {
+	Type  string            `json:"type"`
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// Notifier delivers notifications to a user.
+type Notifier interface {
+	Notify(userID uuid.UUID, n Notification) error
+}
+
+// LogNotifier writes notifications to the server log. Useful in development
+// and as a no-op delivery channel.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (LogNotifier) Notify(userID uuid.UUID, n Notification) error {
+	log.Printf("Notification for user %s: [%s] %s - %s", userID, n.Type, n.Title, n.Body)
+	return nil
+}
@@ -0,0 +1,733 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxPackChallenges = 500
+
+var (
+	ErrPackNotFound          = errors.New("pack not found")
+	ErrInvalidPackChallenges = errors.New("challenge_ids must reference existing challenges without duplicates")
+	ErrPackTooLarge          = fmt.Errorf("a pack cannot hold more than %d challenges", maxPackChallenges)
+	ErrPackFieldsRequired    = errors.New("name and category are required")
+	ErrPackNameEmpty         = errors.New("name cannot be empty")
+	ErrPackCategoryEmpty     = errors.New("category cannot be empty")
+)
+
+type PackService struct {
+	db                  *gorm.DB
+	subscriptionService *SubscriptionService
+	signer              *PackSigner
+}
+
+func NewPackService(db *gorm.DB, subs *SubscriptionService, signer *PackSigner) *PackService {
+	return &PackService{db: db, subscriptionService: subs, signer: signer}
+}
+
+// ListPacks returns every pack with a flag telling whether the caller may
+// download it. Anonymous callers (uuid.Nil) are only entitled to free packs.
+func (s *PackService) ListPacks(userID uuid.UUID) ([]dto.PackResponse, error) {
+	var packs []models.QuestionPack
+	if err := s.db.Order("is_premium ASC, name ASC").Find(&packs).Error; err != nil {
+		return nil, err
+	}
+
+	premium := s.subscriptionService.IsPremium(userID)
+
+	result := make([]dto.PackResponse, 0, len(packs))
+	for _, p := range packs {
+		result = append(result, dto.PackResponse{
+			ID:             p.ID,
+			Name:           p.Name,
+			Description:    p.Description,
+			Category:       p.Category,
+			IsPremium:      p.IsPremium,
+			Entitlement:    s.packEntitlement(&p),
+			ChallengeCount: p.ChallengeCount,
+			Version:        p.Version,
+			Entitled:       !p.IsPremium || premium,
+			UpdatedAt:      p.UpdatedAt,
+		})
+	}
+	return result, nil
+}
+
+// packEntitlement returns the entitlement needed to download the pack, or ""
+// for free packs.
+func (s *PackService) packEntitlement(pack *models.QuestionPack) string {
+	if !pack.IsPremium {
+		return ""
+	}
+	return s.subscriptionService.PremiumEntitlement()
+}
+
+// PackEntitlement returns the entitlement needed to download the pack, or
+// "" for free packs.
+func (s *PackService) PackEntitlement(packID uuid.UUID) (string, error) {
+	var pack models.QuestionPack
+	if err := s.db.Select("id", "is_premium").First(&pack, "id = ?", packID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrPackNotFound
+		}
+		return "", err
+	}
+	return s.packEntitlement(&pack), nil
+}
+
+// checkEntitlement returns an *EntitlementError if userID may not download
+// the pack.
+func (s *PackService) checkEntitlement(pack *models.QuestionPack, userID uuid.UUID) error {
+	if entitlement := s.packEntitlement(pack); entitlement != "" {
+		return s.subscriptionService.RequireEntitlement(userID, entitlement)
+	}
+	return nil
+}
+
+// DownloadPack returns a pack's content for offline caching. When since is
+// a version the client already holds, only the changes after it are
+// returned; any other value yields the full pack.
+func (s *PackService) DownloadPack(packID, userID uuid.UUID, since int) (*dto.PackDownloadResponse, error) {
+	if err := s.dropModeratedChallenges(packID); err != nil {
+		return nil, err
+	}
+
+	var pack models.QuestionPack
+	if err := s.db.First(&pack, "id = ?", packID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPackNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.checkEntitlement(&pack, userID); err != nil {
+		return nil, err
+	}
+
+	var items []models.QuestionPackItem
+	if err := s.db.Preload("Challenge").
+		Where("pack_id = ?", pack.ID).
+		Order("sort_order ASC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	live := make([]models.QuestionPackItem, 0, len(items))
+	for _, item := range items {
+		if item.RemovedVersion == 0 {
+			live = append(live, item)
+		}
+	}
+
+	resp := &dto.PackDownloadResponse{
+		Pack: dto.PackResponse{
+			ID:             pack.ID,
+			Name:           pack.Name,
+			Description:    pack.Description,
+			Category:       pack.Category,
+			IsPremium:      pack.IsPremium,
+			Entitlement:    s.packEntitlement(&pack),
+			ChallengeCount: len(live),
+			Version:        pack.Version,
+			Entitled:       true,
+			UpdatedAt:      pack.UpdatedAt,
+		},
+		Version:     pack.Version,
+		ContentHash: packContentHash(live),
+	}
+
+	if since <= 0 || since > pack.Version {
+		resp.Full = true
+		resp.Challenges = make([]dto.PackChallenge, 0, len(live))
+		for _, item := range live {
+			resp.Challenges = append(resp.Challenges, toPackChallenge(&item))
+		}
+		return resp, nil
+	}
+
+	resp.FromVersion = since
+	for _, item := range items {
+		switch {
+		case item.RemovedVersion > since && item.AddedVersion <= since:
+			resp.Removed = append(resp.Removed, item.ChallengeID)
+		case item.RemovedVersion != 0:
+			// Removed before the client's version, or added and removed since
+		case item.AddedVersion > since:
+			resp.Added = append(resp.Added, toPackChallenge(&item))
+		case item.UpdatedVersion > since:
+			resp.Changed = append(resp.Changed, toPackChallenge(&item))
+		}
+	}
+
+	return resp, nil
+}
+
+// DownloadPackFile returns the pack as a signed .wypack for callers entitled
+// to it, along with a suggested file name.
+func (s *PackService) DownloadPackFile(packID, userID uuid.UUID) ([]byte, string, error) {
+	var pack models.QuestionPack
+	if err := s.db.First(&pack, "id = ?", packID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrPackNotFound
+		}
+		return nil, "", err
+	}
+
+	if err := s.checkEntitlement(&pack, userID); err != nil {
+		return nil, "", err
+	}
+
+	return s.ExportPack(packID)
+}
+
+// ExportPack serialises the pack's live content into a signed .wypack file.
+func (s *PackService) ExportPack(packID uuid.UUID) ([]byte, string, error) {
+	if err := s.dropModeratedChallenges(packID); err != nil {
+		return nil, "", err
+	}
+
+	pack, err := s.GetPack(packID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	challenges := make([]dto.PackChallenge, 0, len(pack.Items))
+	for _, item := range pack.Items {
+		challenges = append(challenges, toPackChallenge(&item))
+	}
+
+	data, err := s.signer.Encode(WypackManifest{
+		PackID:      pack.ID.String(),
+		Name:        pack.Name,
+		Description: pack.Description,
+		Category:    pack.Category,
+		IsPremium:   pack.IsPremium,
+		Version:     pack.Version,
+		ContentHash: packContentHash(pack.Items),
+		CreatedAt:   time.Now().UTC(),
+	}, challenges)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode pack: %w", err)
+	}
+
+	return data, fmt.Sprintf("%s-v%d%s", packFileSlug(pack.Name), pack.Version, WypackExtension), nil
+}
+
+// ImportPack verifies a .wypack and upserts its challenges and pack.
+// Importing the same file twice is a no-op. Challenges that were removed
+// locally (e.g. by moderation) are skipped rather than resurrected.
+func (s *PackService) ImportPack(data []byte) (*dto.PackImportResponse, error) {
+	manifest, challenges, err := s.signer.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	packID, err := uuid.Parse(manifest.PackID)
+	if err != nil || strings.TrimSpace(manifest.Name) == "" || strings.TrimSpace(manifest.Category) == "" {
+		return nil, ErrWypackMalformed
+	}
+	if len(challenges) > maxPackChallenges {
+		return nil, ErrPackTooLarge
+	}
+
+	resp := &dto.PackImportResponse{PackID: packID}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var pack models.QuestionPack
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&pack, "id = ?", packID).Error
+		packExists := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		ordered := make([]dto.PackChallenge, len(challenges))
+		copy(ordered, challenges)
+		sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].SortOrder < ordered[j].SortOrder })
+
+		ids := make([]uuid.UUID, 0, len(ordered))
+		var edited []uuid.UUID
+		seen := make(map[uuid.UUID]bool, len(ordered))
+
+		for _, ch := range ordered {
+			optionA := strings.TrimSpace(ch.OptionA)
+			optionB := strings.TrimSpace(ch.OptionB)
+			category := strings.ToLower(strings.TrimSpace(ch.Category))
+			if ch.ID == uuid.Nil || seen[ch.ID] || optionA == "" || optionB == "" {
+				return ErrWypackMalformed
+			}
+			seen[ch.ID] = true
+
+			var existing models.Challenge
+			err := tx.Unscoped().First(&existing, "id = ?", ch.ID).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Create(&models.Challenge{
+					ID:       ch.ID,
+					OptionA:  optionA,
+					OptionB:  optionB,
+					Category: category,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to create challenge: %w", err)
+				}
+				resp.ChallengesCreated++
+			case err != nil:
+				return err
+			case existing.DeletedAt.Valid:
+				resp.ChallengesSkipped++
+				continue
+			case existing.OptionA != optionA || existing.OptionB != optionB || existing.Category != category:
+				if err := tx.Model(&existing).Updates(map[string]interface{}{
+					"option_a": optionA,
+					"option_b": optionB,
+					"category": category,
+				}).Error; err != nil {
+					return err
+				}
+				edited = append(edited, ch.ID)
+				resp.ChallengesUpdated++
+			}
+			ids = append(ids, ch.ID)
+		}
+
+		// Everything this import changes in the pack lands on one version
+		nextVersion := max(pack.Version+1, manifest.Version)
+		editedInPack := packExists && len(edited) > 0 && s.packContainsAny(tx, pack.ID, edited)
+
+		// Edited challenges change every pack that contains them
+		if err := s.markChallengesChanged(tx, edited, pack.ID, nextVersion); err != nil {
+			return err
+		}
+
+		if !packExists {
+			pack = models.QuestionPack{
+				ID:          packID,
+				Name:        strings.TrimSpace(manifest.Name),
+				Description: strings.TrimSpace(manifest.Description),
+				Category:    strings.ToLower(strings.TrimSpace(manifest.Category)),
+				IsPremium:   manifest.IsPremium,
+				Version:     max(manifest.Version, 1),
+			}
+			if err := tx.Create(&pack).Error; err != nil {
+				return fmt.Errorf("failed to create pack: %w", err)
+			}
+			if _, err := s.replaceItems(tx, &pack, ids, pack.Version); err != nil {
+				return err
+			}
+			resp.PackCreated = true
+			resp.PackChanged = true
+			resp.Version = pack.Version
+			return tx.Model(&pack).Update("challenge_count", pack.ChallengeCount).Error
+		}
+
+		updates := map[string]interface{}{}
+		if pack.DeletedAt.Valid {
+			updates["deleted_at"] = nil
+		}
+		if name := strings.TrimSpace(manifest.Name); name != pack.Name {
+			updates["name"] = name
+		}
+		if description := strings.TrimSpace(manifest.Description); description != pack.Description {
+			updates["description"] = description
+		}
+		if category := strings.ToLower(strings.TrimSpace(manifest.Category)); category != pack.Category {
+			updates["category"] = category
+		}
+		if manifest.IsPremium != pack.IsPremium {
+			updates["is_premium"] = manifest.IsPremium
+		}
+
+		itemsChanged, err := s.replaceItems(tx, &pack, ids, nextVersion)
+		if err != nil {
+			return err
+		}
+		if itemsChanged {
+			updates["challenge_count"] = pack.ChallengeCount
+		}
+
+		resp.Version = pack.Version
+		if len(updates) == 0 && !editedInPack {
+			return nil
+		}
+
+		updates["version"] = nextVersion
+		resp.Version = nextVersion
+		resp.PackChanged = true
+		return tx.Unscoped().Model(&pack).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// markChallengesChanged bumps the version of every pack holding one of the
+// given challenges and stamps those items as changed, so delta downloads
+// ship the new text. Items of ownPackID are stamped with ownVersion and
+// that pack's version is left to the caller, which bumps it once for all
+// of its changes.
+func (s *PackService) markChallengesChanged(tx *gorm.DB, challengeIDs []uuid.UUID, ownPackID uuid.UUID, ownVersion int) error {
+	if len(challengeIDs) == 0 {
+		return nil
+	}
+
+	var packIDs []uuid.UUID
+	tx.Model(&models.QuestionPackItem{}).
+		Where("challenge_id IN ? AND removed_version = 0", challengeIDs).
+		Distinct("pack_id").
+		Pluck("pack_id", &packIDs)
+
+	for _, packID := range packIDs {
+		if packID == ownPackID {
+			if err := tx.Model(&models.QuestionPackItem{}).
+				Where("pack_id = ? AND challenge_id IN ? AND removed_version = 0", packID, challengeIDs).
+				Update("updated_version", ownVersion).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		var pack models.QuestionPack
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&pack, "id = ?", packID).Error; err != nil {
+			continue
+		}
+		nextVersion := pack.Version + 1
+		if err := tx.Model(&models.QuestionPackItem{}).
+			Where("pack_id = ? AND challenge_id IN ? AND removed_version = 0", packID, challengeIDs).
+			Update("updated_version", nextVersion).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&pack).Update("version", nextVersion).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PackService) packContainsAny(tx *gorm.DB, packID uuid.UUID, challengeIDs []uuid.UUID) bool {
+	var count int64
+	tx.Model(&models.QuestionPackItem{}).
+		Where("pack_id = ? AND challenge_id IN ? AND removed_version = 0", packID, challengeIDs).
+		Count(&count)
+	return count > 0
+}
+
+// dropModeratedChallenges tombstones items whose challenge has been removed
+// (e.g. actioned by moderation) and bumps the pack version, so the removal
+// reaches clients on their next sync.
+func (s *PackService) dropModeratedChallenges(packID uuid.UUID) error {
+	var stale []models.QuestionPackItem
+	s.db.Table("question_pack_items").
+		Select("question_pack_items.*").
+		Joins("LEFT JOIN challenges ON challenges.id = question_pack_items.challenge_id").
+		Where("question_pack_items.pack_id = ? AND question_pack_items.removed_version = 0", packID).
+		Where("challenges.id IS NULL OR challenges.deleted_at IS NOT NULL").
+		Find(&stale)
+	if len(stale) == 0 {
+		return nil
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var pack models.QuestionPack
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&pack, "id = ?", packID).Error; err != nil {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(stale))
+		for i, item := range stale {
+			ids[i] = item.ID
+		}
+
+		nextVersion := pack.Version + 1
+		result := tx.Model(&models.QuestionPackItem{}).
+			Where("id IN ? AND removed_version = 0", ids).
+			Update("removed_version", nextVersion)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Another request already reconciled this pack
+			return nil
+		}
+
+		return tx.Model(&pack).Updates(map[string]interface{}{
+			"version":         nextVersion,
+			"challenge_count": gorm.Expr("challenge_count - ?", result.RowsAffected),
+		}).Error
+	})
+}
+
+// --- Admin ---
+
+func (s *PackService) AdminListPacks(limit, offset int) ([]models.QuestionPack, int64, error) {
+	var packs []models.QuestionPack
+	var total int64
+
+	s.db.Model(&models.QuestionPack{}).Count(&total)
+
+	if err := s.db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&packs).Error; err != nil {
+		return nil, 0, err
+	}
+	return packs, total, nil
+}
+
+// GetPack returns a pack with its items and challenges in pack order.
+func (s *PackService) GetPack(packID uuid.UUID) (*models.QuestionPack, error) {
+	var pack models.QuestionPack
+	err := s.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Where("removed_version = 0").Order("sort_order ASC")
+	}).Preload("Items.Challenge").First(&pack, "id = ?", packID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPackNotFound
+		}
+		return nil, err
+	}
+	return &pack, nil
+}
+
+func (s *PackService) CreatePack(req *dto.CreatePackRequest) (*models.QuestionPack, error) {
+	name := strings.TrimSpace(req.Name)
+	category := strings.ToLower(strings.TrimSpace(req.Category))
+	if name == "" || category == "" {
+		return nil, ErrPackFieldsRequired
+	}
+
+	pack := models.QuestionPack{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Category:    category,
+		IsPremium:   req.IsPremium,
+		Version:     1,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&pack).Error; err != nil {
+			return fmt.Errorf("failed to create pack: %w", err)
+		}
+		if _, err := s.replaceItems(tx, &pack, req.ChallengeIDs, pack.Version); err != nil {
+			return err
+		}
+		return tx.Model(&pack).Update("challenge_count", pack.ChallengeCount).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPack(pack.ID)
+}
+
+// UpdatePack applies a partial update and bumps the version if anything
+// about the pack's content actually changed.
+func (s *PackService) UpdatePack(packID uuid.UUID, req *dto.UpdatePackRequest) (*models.QuestionPack, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var pack models.QuestionPack
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&pack, "id = ?", packID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPackNotFound
+			}
+			return err
+		}
+
+		nextVersion := pack.Version + 1
+		updates := map[string]interface{}{}
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			if name == "" {
+				return ErrPackNameEmpty
+			}
+			if name != pack.Name {
+				updates["name"] = name
+			}
+		}
+		if req.Description != nil && strings.TrimSpace(*req.Description) != pack.Description {
+			updates["description"] = strings.TrimSpace(*req.Description)
+		}
+		if req.Category != nil {
+			category := strings.ToLower(strings.TrimSpace(*req.Category))
+			if category == "" {
+				return ErrPackCategoryEmpty
+			}
+			if category != pack.Category {
+				updates["category"] = category
+			}
+		}
+		if req.IsPremium != nil && *req.IsPremium != pack.IsPremium {
+			updates["is_premium"] = *req.IsPremium
+		}
+
+		if req.ChallengeIDs != nil {
+			changed, err := s.replaceItems(tx, &pack, req.ChallengeIDs, nextVersion)
+			if err != nil {
+				return err
+			}
+			if changed {
+				updates["challenge_count"] = pack.ChallengeCount
+			}
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		updates["version"] = nextVersion
+		return tx.Model(&pack).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPack(packID)
+}
+
+func (s *PackService) DeletePack(packID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.QuestionPack{}, "id = ?", packID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPackNotFound
+		}
+		return tx.Where("pack_id = ?", packID).Delete(&models.QuestionPackItem{}).Error
+	})
+}
+
+// replaceItems makes challengeIDs the pack's ordered list of live items.
+// Items that move or reappear are stamped with version, and items that drop
+// out are tombstoned at version. It reports whether anything changed.
+func (s *PackService) replaceItems(tx *gorm.DB, pack *models.QuestionPack, challengeIDs []uuid.UUID, version int) (bool, error) {
+	if len(challengeIDs) > maxPackChallenges {
+		return false, ErrPackTooLarge
+	}
+
+	seen := make(map[uuid.UUID]bool, len(challengeIDs))
+	for _, id := range challengeIDs {
+		if seen[id] {
+			return false, ErrInvalidPackChallenges
+		}
+		seen[id] = true
+	}
+
+	if len(challengeIDs) > 0 {
+		var found int64
+		tx.Model(&models.Challenge{}).Where("id IN ?", challengeIDs).Count(&found)
+		if int(found) != len(challengeIDs) {
+			return false, ErrInvalidPackChallenges
+		}
+	}
+
+	var existing []models.QuestionPackItem
+	if err := tx.Where("pack_id = ?", pack.ID).Find(&existing).Error; err != nil {
+		return false, err
+	}
+
+	byChallenge := make(map[uuid.UUID]*models.QuestionPackItem, len(existing))
+	for i := range existing {
+		byChallenge[existing[i].ChallengeID] = &existing[i]
+	}
+
+	changed := false
+	for i, id := range challengeIDs {
+		item, ok := byChallenge[id]
+		switch {
+		case !ok:
+			newItem := models.QuestionPackItem{
+				PackID:         pack.ID,
+				ChallengeID:    id,
+				SortOrder:      i,
+				AddedVersion:   version,
+				UpdatedVersion: version,
+			}
+			if err := tx.Omit("Challenge").Create(&newItem).Error; err != nil {
+				return false, fmt.Errorf("failed to save pack item: %w", err)
+			}
+			changed = true
+		case item.RemovedVersion != 0:
+			// Re-added after removal: clients dropped it, so it counts as new
+			if err := tx.Model(item).Updates(map[string]interface{}{
+				"sort_order":      i,
+				"added_version":   version,
+				"updated_version": version,
+				"removed_version": 0,
+			}).Error; err != nil {
+				return false, err
+			}
+			changed = true
+		case item.SortOrder != i:
+			if err := tx.Model(item).Updates(map[string]interface{}{
+				"sort_order":      i,
+				"updated_version": version,
+			}).Error; err != nil {
+				return false, err
+			}
+			changed = true
+		}
+	}
+
+	for _, item := range existing {
+		if item.RemovedVersion == 0 && !seen[item.ChallengeID] {
+			if err := tx.Model(&item).Update("removed_version", version).Error; err != nil {
+				return false, err
+			}
+			changed = true
+		}
+	}
+
+	pack.ChallengeCount = len(challengeIDs)
+	return changed, nil
+}
+
+func toPackChallenge(item *models.QuestionPackItem) dto.PackChallenge {
+	return dto.PackChallenge{
+		ID:        item.ChallengeID,
+		OptionA:   item.Challenge.OptionA,
+		OptionB:   item.Challenge.OptionB,
+		Category:  item.Challenge.Category,
+		SortOrder: item.SortOrder,
+	}
+}
+
+// packFileSlug turns a pack name into a safe file name stem.
+func packFileSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "pack"
+	}
+	return slug
+}
+
+// packContentHash fingerprints the live, ordered content of a pack so clients
+// can verify that applying a delta left them with the same data.
+func packContentHash(live []models.QuestionPackItem) string {
+	h := sha256.New()
+	for _, item := range live {
+		fmt.Fprintf(h, "%s\x1f%d\x1f%s\x1f%s\x1f%s\n",
+			item.ChallengeID, item.SortOrder,
+			item.Challenge.OptionA, item.Challenge.OptionB, item.Challenge.Category)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
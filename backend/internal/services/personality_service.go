@@ -0,0 +1,233 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Votes needed before there's anything to write about
+	minVotesForPersonality = 5
+	// A cached summary is regenerated once the user casts this many more votes
+	personalityRefreshVotes = 25
+	// A fallback cached after an LLM failure is retried after this long
+	personalityRetryInterval = time.Hour
+	personalityDigestPicks   = 30
+	maxPersonalityRunes      = 600
+)
+
+const personalitySystemPrompt = `You write short, playful personality readings for a "Would You Rather" game, based on which options a player picked.
+
+Rules:
+1. Write 2-4 sentences, under 80 words, addressed to the player as "you"
+2. Be warm, funny and family-friendly; never mean, never about health, politics, religion, or sensitive traits
+3. Base it only on the picks and numbers given; do not invent names or personal details
+4. Return ONLY the write-up as plain text, no title, no markdown, no emojis`
+
+var fallbackPersonalities = map[string]string{
+	"newcomer":      "You're just getting started, and every pick tells us a little more about you. Keep voting and your personality reading will come into focus.",
+	"crowd_pleaser": "You've got a finger on the pulse: when the crowd leans one way, you're usually right there with them. Reliable, relatable, and great at reading the room.",
+	"wild_card":     "You keep everyone guessing. Some days you're with the crowd, some days you're off on your own adventure, and that's exactly what makes you fun.",
+	"rebel":         "Why take the obvious road? You pick the options most people skip, and you own every one of them. Certified trailblazer.",
+}
+
+type PersonalityService struct {
+	db                *gorm.DB
+	generator         *QuestionGeneratorService
+	moderationService *ModerationService
+	statsService      *StatsService
+	generating        sync.Map // user ID -> struct{}, guards against duplicate LLM calls
+}
+
+func NewPersonalityService(db *gorm.DB, generator *QuestionGeneratorService, moderation *ModerationService, stats *StatsService) *PersonalityService {
+	return &PersonalityService{
+		db:                db,
+		generator:         generator,
+		moderationService: moderation,
+		statsService:      stats,
+	}
+}
+
+// GetPersonality returns the user's personality write-up, generating a new
+// one when the cached summary is stale.
+func (s *PersonalityService) GetPersonality(userID uuid.UUID) (*dto.PersonalityResponse, error) {
+	stats, err := s.statsService.GetUserStats(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.TotalVotes < minVotesForPersonality {
+		return &dto.PersonalityResponse{
+			Summary:       fallbackPersonalities["newcomer"],
+			Badge:         stats.Badge,
+			Source:        "fallback",
+			VotesAnalyzed: stats.TotalVotes,
+			RefreshIn:     minVotesForPersonality - stats.TotalVotes,
+			GeneratedAt:   time.Now(),
+		}, nil
+	}
+
+	var cached models.PersonalitySummary
+	err = s.db.Where("user_id = ?", userID).First(&cached).Error
+	hasCached := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if hasCached && !s.isStale(&cached, stats.TotalVotes) {
+		return toPersonalityResponse(&cached, stats), nil
+	}
+
+	if _, busy := s.generating.LoadOrStore(userID, struct{}{}); busy {
+		if hasCached {
+			return toPersonalityResponse(&cached, stats), nil
+		}
+		return &dto.PersonalityResponse{
+			Summary:       fallbackPersonality(stats),
+			Badge:         stats.Badge,
+			Source:        "fallback",
+			VotesAnalyzed: stats.TotalVotes,
+			GeneratedAt:   time.Now(),
+		}, nil
+	}
+	defer s.generating.Delete(userID)
+
+	summary := models.PersonalitySummary{
+		UserID:    userID,
+		Summary:   fallbackPersonality(stats),
+		Source:    "fallback",
+		VoteCount: stats.TotalVotes,
+	}
+	if s.generator.IsAvailable() {
+		text, err := s.generate(userID, stats)
+		if err != nil {
+			log.Printf("Personality generation failed for user %s: %v", userID, err)
+		} else {
+			summary.Summary = text
+			summary.Source = "ai"
+		}
+	}
+
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"summary", "source", "vote_count", "updated_at"}),
+	}).Create(&summary).Error; err != nil {
+		return nil, err
+	}
+	summary.UpdatedAt = time.Now()
+
+	return toPersonalityResponse(&summary, stats), nil
+}
+
+func (s *PersonalityService) isStale(cached *models.PersonalitySummary, totalVotes int) bool {
+	if totalVotes >= cached.VoteCount+personalityRefreshVotes {
+		return true
+	}
+	// Upgrade a fallback once the LLM is back, without hammering it
+	return cached.Source == "fallback" && s.generator.IsAvailable() &&
+		time.Since(cached.UpdatedAt) > personalityRetryInterval
+}
+
+// generate asks the LLM for a write-up from an anonymized digest: only
+// aggregate numbers and the text of recent picks, never user identifiers.
+func (s *PersonalityService) generate(userID uuid.UUID, stats *dto.UserStatsResponse) (string, error) {
+	digest, err := s.digest(userID, stats)
+	if err != nil {
+		return "", err
+	}
+
+	text, err := s.generator.Complete(personalitySystemPrompt, digest, 0.8, 300)
+	if err != nil {
+		return "", err
+	}
+
+	text = strings.Join(strings.Fields(strings.Trim(text, "\"'`")), " ")
+	if text == "" {
+		return "", fmt.Errorf("empty personality summary")
+	}
+	if utf8.RuneCountInString(text) > maxPersonalityRunes {
+		return "", fmt.Errorf("personality summary too long")
+	}
+	if clean, _ := s.moderationService.FilterContent(text); !clean {
+		return "", fmt.Errorf("personality summary failed content filter")
+	}
+
+	return text, nil
+}
+
+func (s *PersonalityService) digest(userID uuid.UUID, stats *dto.UserStatsResponse) (string, error) {
+	var picks []struct {
+		Choice  string
+		OptionA string
+		OptionB string
+	}
+	if err := s.db.Table("votes").
+		Select("votes.choice, challenges.option_a, challenges.option_b").
+		Joins("JOIN challenges ON challenges.id = votes.challenge_id AND challenges.deleted_at IS NULL").
+		Where("votes.user_id = ? AND votes.deleted_at IS NULL", userID).
+		Order("votes.created_at DESC").
+		Limit(personalityDigestPicks).
+		Scan(&picks).Error; err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Total votes: %d\n", stats.TotalVotes)
+	fmt.Fprintf(&b, "Sides with the majority: %d%% of the time (%s)\n", stats.MainstreamScore, stats.Badge.Name)
+	if len(stats.Categories) > 0 {
+		b.WriteString("Favorite categories:")
+		for i, cat := range stats.Categories {
+			if i == 5 {
+				break
+			}
+			fmt.Fprintf(&b, " %s %d%%", cat.Category, cat.Percent)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Recent picks:\n")
+	for _, p := range picks {
+		chosen, rejected := p.OptionA, p.OptionB
+		if p.Choice == "B" {
+			chosen, rejected = p.OptionB, p.OptionA
+		}
+		fmt.Fprintf(&b, "- %s (over: %s)\n",
+			s.moderationService.SanitizeContent(chosen),
+			s.moderationService.SanitizeContent(rejected))
+	}
+
+	return b.String(), nil
+}
+
+func fallbackPersonality(stats *dto.UserStatsResponse) string {
+	text, ok := fallbackPersonalities[stats.Badge.ID]
+	if !ok {
+		text = fallbackPersonalities["wild_card"]
+	}
+	if stats.FavoriteCategory != "" {
+		text += fmt.Sprintf(" Your go-to category? %s.", stats.FavoriteCategory)
+	}
+	return text
+}
+
+func toPersonalityResponse(summary *models.PersonalitySummary, stats *dto.UserStatsResponse) *dto.PersonalityResponse {
+	return &dto.PersonalityResponse{
+		Summary:       summary.Summary,
+		Badge:         stats.Badge,
+		Source:        summary.Source,
+		VotesAnalyzed: summary.VoteCount,
+		RefreshIn:     max(summary.VoteCount+personalityRefreshVotes-stats.TotalVotes, 0),
+		GeneratedAt:   summary.UpdatedAt,
+	}
+}
@@ -0,0 +1,609 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// XP sources
+const (
+	XPSourceVote     = "vote"
+	XPSourceDaily    = "daily"
+	XPSourceShare    = "share"
+	XPSourceLobbyWin = "lobby_win"
+)
+
+var ErrSeasonNotFound = errors.New("season not found")
+
+const (
+	defaultLeaderboardSize = 50
+	maxLeaderboardSize     = 100
+)
+
+type xpRule struct {
+	amount   int
+	dailyCap int // Actions of this kind that earn XP per day
+}
+
+// xpRules sets the XP per action and a daily cap per source, so grinding
+// one kind of action can't dominate the ladder.
+var xpRules = map[string]xpRule{
+	XPSourceVote:     {amount: 10, dailyCap: 50},
+	XPSourceDaily:    {amount: 25, dailyCap: 1},
+	XPSourceShare:    {amount: 15, dailyCap: 5},
+	XPSourceLobbyWin: {amount: 50, dailyCap: 10},
+}
+
+var xpSourceOrder = []string{XPSourceVote, XPSourceDaily, XPSourceShare, XPSourceLobbyWin}
+
+// seasonPrize grants entitlement for duration to finishers ranked maxRank
+// or better (and below the previous tier).
+type seasonPrize struct {
+	maxRank     int
+	entitlement string
+	duration    time.Duration
+}
+
+// ProgressionService awards XP, derives levels and runs the monthly
+// ranked seasons.
+type ProgressionService struct {
+	db            *gorm.DB
+	subscriptions *SubscriptionService
+	userService   *UserService
+	notifier      Notifier
+	loc           *time.Location
+	startDay      int
+	prizes        []seasonPrize
+}
+
+func NewProgressionService(db *gorm.DB, cfg *config.Config, subs *SubscriptionService, userService *UserService, notifier Notifier) (*ProgressionService, error) {
+	loc, err := time.LoadLocation(cfg.SeasonTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SEASON_TIMEZONE: %w", err)
+	}
+	if cfg.SeasonStartDay < 1 || cfg.SeasonStartDay > 28 {
+		return nil, fmt.Errorf("SEASON_START_DAY must be between 1 and 28")
+	}
+	prizes, err := parseSeasonPrizes(cfg.SeasonPrizes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProgressionService{
+		db:            db,
+		subscriptions: subs,
+		userService:   userService,
+		notifier:      notifier,
+		loc:           loc,
+		startDay:      cfg.SeasonStartDay,
+		prizes:        prizes,
+	}, nil
+}
+
+// AwardVote grants XP for a vote, plus the daily bonus when it is on
+// today's daily challenge. Failures are logged; call it from a goroutine.
+func (s *ProgressionService) AwardVote(userID, challengeID uuid.UUID) {
+	s.award(userID, XPSourceVote, challengeID.String())
+
+	today := time.Now().Truncate(24 * time.Hour)
+	var isDaily int64
+	s.db.Model(&models.Challenge{}).
+		Where("id = ? AND is_daily = ? AND daily_date = ?", challengeID, true, today).
+		Count(&isDaily)
+	if isDaily > 0 {
+		s.award(userID, XPSourceDaily, challengeID.String())
+	}
+}
+
+// AwardShare grants XP for sharing a challenge for the first time.
+func (s *ProgressionService) AwardShare(userID, challengeID uuid.UUID) {
+	s.award(userID, XPSourceShare, challengeID.String())
+}
+
+// AwardLobbyWin grants XP for winning a multiplayer lobby.
+func (s *ProgressionService) AwardLobbyWin(userID, lobbyID uuid.UUID) {
+	s.award(userID, XPSourceLobbyWin, lobbyID.String())
+}
+
+func (s *ProgressionService) award(userID uuid.UUID, source, refKey string) {
+	if userID == uuid.Nil {
+		return
+	}
+	amount, levelBefore, levelAfter, err := s.Award(userID, source, refKey)
+	if err != nil {
+		log.Printf("Failed to award %s XP to user %s: %v", source, userID, err)
+		return
+	}
+	if amount > 0 && levelAfter > levelBefore {
+		if err := s.notifier.Notify(userID, Notification{
+			Type:  "level_up",
+			Title: fmt.Sprintf("Level %d!", levelAfter),
+			Body:  fmt.Sprintf("You reached level %d. Keep voting to climb the season ladder.", levelAfter),
+			Data:  map[string]string{"level": strconv.Itoa(levelAfter)},
+		}); err != nil {
+			log.Printf("Failed to send level-up notification to user %s: %v", userID, err)
+		}
+	}
+}
+
+// Award grants the XP for one action. Nothing is awarded if the same
+// action (source and refKey) was rewarded before or the source's daily cap
+// is used up. It returns the XP awarded and the level before and after.
+func (s *ProgressionService) Award(userID uuid.UUID, source, refKey string) (int, int, int, error) {
+	rule, ok := xpRules[source]
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("unknown XP source %q", source)
+	}
+
+	now := time.Now()
+	season, err := s.ensureSeason(now)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	awarded, before, after := 0, 0, 0
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the user's total so concurrent awards see each other's caps
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserXP{UserID: userID}).Error; err != nil {
+			return err
+		}
+		var total models.UserXP
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&total, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		before, after = levelForXP(total.TotalXP), levelForXP(total.TotalXP)
+
+		var today int64
+		tx.Model(&models.XPEvent{}).
+			Where("user_id = ? AND source = ? AND created_at >= ?", userID, source, s.startOfDay(now)).
+			Count(&today)
+		if int(today) >= rule.dailyCap {
+			return nil
+		}
+
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.XPEvent{
+			UserID:   userID,
+			Source:   source,
+			RefKey:   refKey,
+			Amount:   rule.amount,
+			SeasonID: season.ID,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&total).Update("total_xp", gorm.Expr("total_xp + ?", rule.amount)).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "season_id"}, {Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"xp": gorm.Expr("season_scores.xp + ?", rule.amount), "updated_at": now}),
+		}).Create(&models.SeasonScore{SeasonID: season.ID, UserID: userID, XP: rule.amount}).Error; err != nil {
+			return err
+		}
+
+		awarded = rule.amount
+		after = levelForXP(total.TotalXP + rule.amount)
+		return nil
+	})
+	return awarded, before, after, err
+}
+
+// GetProgress returns the user's level, current season standing and how
+// much of today's XP caps they have used.
+func (s *ProgressionService) GetProgress(userID uuid.UUID) (*dto.ProgressResponse, error) {
+	var total models.UserXP
+	if err := s.db.Where("user_id = ?", userID).First(&total).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	season, err := s.ensureSeason(now)
+	if err != nil {
+		return nil, err
+	}
+
+	level := levelForXP(total.TotalXP)
+	resp := &dto.ProgressResponse{
+		TotalXP:     total.TotalXP,
+		Level:       level,
+		LevelXP:     total.TotalXP - xpForLevel(level),
+		LevelSpan:   xpForLevel(level+1) - xpForLevel(level),
+		NextLevelAt: xpForLevel(level + 1),
+		Season:      dto.SeasonStanding{Season: toSeasonResponse(season)},
+		DailyCaps:   make([]dto.XPCapStatus, 0, len(xpSourceOrder)),
+	}
+
+	var score models.SeasonScore
+	if err := s.db.Where("season_id = ? AND user_id = ?", season.ID, userID).First(&score).Error; err == nil {
+		resp.Season.XP = score.XP
+		resp.Season.Rank = s.liveRank(&score)
+	}
+
+	var counts []struct {
+		Source string
+		Count  int
+	}
+	s.db.Model(&models.XPEvent{}).
+		Select("source, COUNT(*) AS count").
+		Where("user_id = ? AND created_at >= ?", userID, s.startOfDay(now)).
+		Group("source").
+		Scan(&counts)
+	earned := map[string]int{}
+	for _, c := range counts {
+		earned[c.Source] = c.Count
+	}
+	for _, source := range xpSourceOrder {
+		rule := xpRules[source]
+		resp.DailyCaps = append(resp.DailyCaps, dto.XPCapStatus{
+			Source: source,
+			Earned: earned[source],
+			Cap:    rule.dailyCap,
+			XPEach: rule.amount,
+		})
+	}
+	return resp, nil
+}
+
+// SeasonHistory returns the user's standing in every season they scored
+// in, newest first.
+func (s *ProgressionService) SeasonHistory(userID uuid.UUID) ([]dto.SeasonStanding, error) {
+	var scores []models.SeasonScore
+	if err := s.db.Where("user_id = ?", userID).Order("season_id DESC").Find(&scores).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(scores))
+	for i, sc := range scores {
+		ids[i] = sc.SeasonID
+	}
+	seasons := map[string]models.Season{}
+	if len(ids) > 0 {
+		var rows []models.Season
+		s.db.Where("id IN ?", ids).Find(&rows)
+		for _, se := range rows {
+			seasons[se.ID] = se
+		}
+	}
+
+	history := make([]dto.SeasonStanding, 0, len(scores))
+	for i := range scores {
+		sc := &scores[i]
+		season, ok := seasons[sc.SeasonID]
+		if !ok {
+			continue
+		}
+		standing := dto.SeasonStanding{Season: toSeasonResponse(&season), XP: sc.XP, Prize: sc.Prize}
+		if sc.FinalRank != nil {
+			standing.Rank = *sc.FinalRank
+		} else {
+			standing.Rank = s.liveRank(sc)
+		}
+		history = append(history, standing)
+	}
+	return history, nil
+}
+
+// ListSeasons returns every season, newest first.
+func (s *ProgressionService) ListSeasons() ([]dto.SeasonResponse, error) {
+	if _, err := s.ensureSeason(time.Now()); err != nil {
+		return nil, err
+	}
+	var seasons []models.Season
+	if err := s.db.Order("starts_at DESC").Find(&seasons).Error; err != nil {
+		return nil, err
+	}
+	resp := make([]dto.SeasonResponse, len(seasons))
+	for i := range seasons {
+		resp[i] = toSeasonResponse(&seasons[i])
+	}
+	return resp, nil
+}
+
+// Leaderboard returns the top of a season's ladder. seasonID may be
+// "current". Finalized seasons are ordered by their recorded final ranks.
+func (s *ProgressionService) Leaderboard(seasonID string, viewerID uuid.UUID, limit int) (*dto.LeaderboardResponse, error) {
+	if limit <= 0 || limit > maxLeaderboardSize {
+		limit = defaultLeaderboardSize
+	}
+
+	var season models.Season
+	if seasonID == "current" {
+		current, err := s.ensureSeason(time.Now())
+		if err != nil {
+			return nil, err
+		}
+		season = *current
+	} else if err := s.db.First(&season, "id = ?", seasonID).Error; err != nil {
+		return nil, ErrSeasonNotFound
+	}
+
+	var rows []struct {
+		UserID      uuid.UUID
+		XP          int
+		FinalRank   *int
+		DisplayName string
+		AvatarKey   string
+		TotalXP     int
+	}
+	q := s.db.Table("season_scores").
+		Select("season_scores.user_id, season_scores.xp, season_scores.final_rank, users.display_name, users.avatar_key, COALESCE(user_xp.total_xp, 0) AS total_xp").
+		Joins("JOIN users ON users.id = season_scores.user_id AND users.deleted_at IS NULL AND users.deletion_requested_at IS NULL").
+		Joins("LEFT JOIN user_xp ON user_xp.user_id = season_scores.user_id").
+		Where("season_scores.season_id = ? AND season_scores.xp > 0", season.ID)
+	if season.FinalizedAt != nil {
+		q = q.Where("season_scores.final_rank IS NOT NULL").Order("season_scores.final_rank")
+	} else {
+		q = q.Order("season_scores.xp DESC, season_scores.updated_at ASC")
+	}
+	if err := q.Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	resp := &dto.LeaderboardResponse{
+		Season:  toSeasonResponse(&season),
+		Entries: make([]dto.LeaderboardEntry, 0, len(rows)),
+	}
+	for i, r := range rows {
+		rank := i + 1
+		if r.FinalRank != nil {
+			rank = *r.FinalRank
+		}
+		entry := dto.LeaderboardEntry{
+			Rank:        rank,
+			UserID:      r.UserID,
+			DisplayName: r.DisplayName,
+			AvatarURL:   s.userService.AvatarURL(&models.User{AvatarKey: r.AvatarKey}),
+			XP:          r.XP,
+			Level:       levelForXP(r.TotalXP),
+		}
+		resp.Entries = append(resp.Entries, entry)
+		if r.UserID == viewerID {
+			me := entry
+			resp.Me = &me
+		}
+	}
+
+	if resp.Me == nil && viewerID != uuid.Nil {
+		var score models.SeasonScore
+		if err := s.db.Where("season_id = ? AND user_id = ? AND xp > 0", season.ID, viewerID).First(&score).Error; err == nil {
+			var user models.User
+			s.db.Select("id", "display_name", "avatar_key").First(&user, "id = ?", viewerID)
+			var total models.UserXP
+			s.db.Where("user_id = ?", viewerID).First(&total)
+
+			rank := 0
+			if score.FinalRank != nil {
+				rank = *score.FinalRank
+			} else if season.FinalizedAt == nil {
+				rank = s.liveRank(&score)
+			}
+			if rank > 0 {
+				resp.Me = &dto.LeaderboardEntry{
+					Rank:        rank,
+					UserID:      viewerID,
+					DisplayName: user.DisplayName,
+					AvatarURL:   s.userService.AvatarURL(&user),
+					XP:          score.XP,
+					Level:       levelForXP(total.TotalXP),
+				}
+			}
+		}
+	}
+	return resp, nil
+}
+
+// FinalizeSeasons closes every season that has ended: it records final
+// ranks, grants the configured prizes as entitlements and notifies the
+// winners. The next season starts from zero on its first XP award.
+func (s *ProgressionService) FinalizeSeasons(ctx context.Context) error {
+	var due []models.Season
+	if err := s.db.Where("finalized_at IS NULL AND ends_at <= ?", time.Now()).Order("ends_at").Find(&due).Error; err != nil {
+		return err
+	}
+
+	for i := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.finalize(&due[i]); err != nil {
+			log.Printf("Failed to finalize season %s: %v", due[i].ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *ProgressionService) finalize(season *models.Season) error {
+	var winners []models.SeasonScore
+	source := "season:" + season.ID
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Claim the season so a concurrent run skips it
+		res := tx.Model(&models.Season{}).
+			Where("id = ? AND finalized_at IS NULL", season.ID).
+			Update("finalized_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		// Pending-deletion accounts are left unranked, matching the live ladder
+		if err := tx.Exec(`UPDATE season_scores ss SET final_rank = r.rank
+			FROM (
+				SELECT sc.user_id, ROW_NUMBER() OVER (ORDER BY sc.xp DESC, sc.updated_at ASC) AS rank
+				FROM season_scores sc
+				JOIN users u ON u.id = sc.user_id AND u.deleted_at IS NULL AND u.deletion_requested_at IS NULL
+				WHERE sc.season_id = ? AND sc.xp > 0
+			) r
+			WHERE ss.season_id = ? AND ss.user_id = r.user_id`, season.ID, season.ID).Error; err != nil {
+			return err
+		}
+
+		prevRank := 0
+		for _, prize := range s.prizes {
+			var tier []models.SeasonScore
+			if err := tx.Where("season_id = ? AND final_rank > ? AND final_rank <= ?", season.ID, prevRank, prize.maxRank).
+				Find(&tier).Error; err != nil {
+				return err
+			}
+			for i := range tier {
+				if err := s.subscriptions.GrantEntitlement(tx, tier[i].UserID, prize.entitlement, source, prize.duration); err != nil {
+					return err
+				}
+				if err := tx.Model(&tier[i]).Update("prize", prize.entitlement).Error; err != nil {
+					return err
+				}
+				tier[i].Prize = prize.entitlement
+			}
+			winners = append(winners, tier...)
+			prevRank = prize.maxRank
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, w := range winners {
+		if err := s.notifier.Notify(w.UserID, Notification{
+			Type:  "season_prize",
+			Title: fmt.Sprintf("You finished #%d this season!", *w.FinalRank),
+			Body:  fmt.Sprintf("Your prize (%s) has been added to your account.", w.Prize),
+			Data:  map[string]string{"season_id": season.ID, "entitlement": w.Prize},
+		}); err != nil {
+			log.Printf("Failed to send season prize notification to user %s: %v", w.UserID, err)
+		}
+	}
+
+	log.Printf("Finalized season %s (%d prize winners)", season.ID, len(winners))
+	return nil
+}
+
+// ensureSeason returns the season containing t, creating it if needed.
+// New seasons start where the previous one ended, so changing
+// SEASON_START_DAY never makes seasons overlap.
+func (s *ProgressionService) ensureSeason(t time.Time) (*models.Season, error) {
+	var season models.Season
+	if err := s.db.Where("starts_at <= ? AND ends_at > ?", t, t).First(&season).Error; err == nil {
+		return &season, nil
+	}
+
+	start, end := s.seasonBounds(t)
+	var latest models.Season
+	if err := s.db.Order("ends_at DESC").First(&latest).Error; err == nil && latest.EndsAt.After(start) {
+		start = latest.EndsAt
+	}
+
+	season = models.Season{ID: start.In(s.loc).Format("2006-01-02"), StartsAt: start, EndsAt: end}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&season).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.First(&season, "id = ?", season.ID).Error; err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// seasonBounds returns the configured monthly period containing t.
+func (s *ProgressionService) seasonBounds(t time.Time) (time.Time, time.Time) {
+	local := t.In(s.loc)
+	start := time.Date(local.Year(), local.Month(), s.startDay, 0, 0, 0, 0, s.loc)
+	if local.Before(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start, start.AddDate(0, 1, 0)
+}
+
+// startOfDay is midnight of t's day in the season timezone; daily XP caps
+// reset then.
+func (s *ProgressionService) startOfDay(t time.Time) time.Time {
+	local := t.In(s.loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.loc)
+}
+
+// liveRank is a score's position in an unfinalized season. Ties go to
+// whoever reached the score first.
+func (s *ProgressionService) liveRank(score *models.SeasonScore) int {
+	if score.XP <= 0 {
+		return 0
+	}
+	var ahead int64
+	s.db.Table("season_scores").
+		Joins("JOIN users ON users.id = season_scores.user_id AND users.deleted_at IS NULL AND users.deletion_requested_at IS NULL").
+		Where("season_scores.season_id = ? AND (season_scores.xp > ? OR (season_scores.xp = ? AND season_scores.updated_at < ?))",
+			score.SeasonID, score.XP, score.XP, score.UpdatedAt).
+		Count(&ahead)
+	return int(ahead) + 1
+}
+
+// xpForLevel is the total XP needed to reach level. Each level takes 100
+// XP more than the one before: 0, 100, 300, 600, 1000, ...
+func xpForLevel(level int) int {
+	return 50 * level * (level - 1)
+}
+
+func levelForXP(xp int) int {
+	level := 1
+	for xpForLevel(level+1) <= xp {
+		level++
+	}
+	return level
+}
+
+func toSeasonResponse(season *models.Season) dto.SeasonResponse {
+	return dto.SeasonResponse{
+		ID:        season.ID,
+		StartsAt:  season.StartsAt,
+		EndsAt:    season.EndsAt,
+		Finalized: season.FinalizedAt != nil,
+	}
+}
+
+// parseSeasonPrizes parses SEASON_PRIZES ("1:premium:720h,10:premium:168h")
+// into tiers ordered by rank.
+func parseSeasonPrizes(raw string) ([]seasonPrize, error) {
+	var prizes []seasonPrize
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid SEASON_PRIZES entry %q, want max_rank:entitlement:duration", entry)
+		}
+		rank, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || rank < 1 {
+			return nil, fmt.Errorf("invalid rank in SEASON_PRIZES entry %q", entry)
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(parts[2]))
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid duration in SEASON_PRIZES entry %q", entry)
+		}
+		entitlement := strings.TrimSpace(parts[1])
+		if entitlement == "" {
+			return nil, fmt.Errorf("missing entitlement in SEASON_PRIZES entry %q", entry)
+		}
+		prizes = append(prizes, seasonPrize{maxRank: rank, entitlement: entitlement, duration: duration})
+	}
+	sort.Slice(prizes, func(i, j int) bool { return prizes[i].maxRank < prizes[j].maxRank })
+	return prizes, nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+)
+
+func TestLevelForXP(t *testing.T) {
+	tests := []struct{ xp, level int }{
+		{0, 1},
+		{99, 1},
+		{100, 2},
+		{299, 2},
+		{300, 3},
+		{4500, 10},
+		{5499, 10},
+		{5500, 11},
+	}
+	for _, tt := range tests {
+		if got := levelForXP(tt.xp); got != tt.level {
+			t.Errorf("levelForXP(%d) = %d, want %d", tt.xp, got, tt.level)
+		}
+	}
+	for level := 1; level <= 50; level++ {
+		if got := levelForXP(xpForLevel(level)); got != level {
+			t.Errorf("levelForXP(xpForLevel(%d)) = %d", level, got)
+		}
+	}
+}
+
+func TestParseSeasonPrizes(t *testing.T) {
+	prizes, err := parseSeasonPrizes(" 10:premium:168h, 1:premium:720h ,,3:supporter:336h")
+	if err != nil {
+		t.Fatalf("parseSeasonPrizes: %v", err)
+	}
+	want := []seasonPrize{
+		{maxRank: 1, entitlement: "premium", duration: 720 * time.Hour},
+		{maxRank: 3, entitlement: "supporter", duration: 336 * time.Hour},
+		{maxRank: 10, entitlement: "premium", duration: 168 * time.Hour},
+	}
+	if !reflect.DeepEqual(prizes, want) {
+		t.Errorf("parseSeasonPrizes = %+v, want %+v", prizes, want)
+	}
+
+	if prizes, err := parseSeasonPrizes(""); err != nil || len(prizes) != 0 {
+		t.Errorf("parseSeasonPrizes(\"\") = %v, %v; want no prizes", prizes, err)
+	}
+
+	for _, raw := range []string{
+		"1:premium",
+		"1:premium:720h:extra",
+		"first:premium:720h",
+		"0:premium:720h",
+		"1::720h",
+		"1:premium:a month",
+		"1:premium:-1h",
+	} {
+		if _, err := parseSeasonPrizes(raw); err == nil {
+			t.Errorf("parseSeasonPrizes(%q) succeeded, want an error", raw)
+		}
+	}
+}
+
+func TestSeasonBounds(t *testing.T) {
+	s, err := NewProgressionService(nil, &config.Config{SeasonTimezone: "Europe/Istanbul", SeasonStartDay: 15}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewProgressionService: %v", err)
+	}
+	ist := s.loc
+
+	tests := []struct {
+		name       string
+		at         time.Time
+		start, end time.Time
+	}{
+		{
+			"mid season",
+			time.Date(2026, 3, 20, 12, 0, 0, 0, ist),
+			time.Date(2026, 3, 15, 0, 0, 0, 0, ist), time.Date(2026, 4, 15, 0, 0, 0, 0, ist),
+		},
+		{
+			"before the start day",
+			time.Date(2026, 3, 10, 12, 0, 0, 0, ist),
+			time.Date(2026, 2, 15, 0, 0, 0, 0, ist), time.Date(2026, 3, 15, 0, 0, 0, 0, ist),
+		},
+		{
+			"exactly at the start",
+			time.Date(2026, 3, 15, 0, 0, 0, 0, ist),
+			time.Date(2026, 3, 15, 0, 0, 0, 0, ist), time.Date(2026, 4, 15, 0, 0, 0, 0, ist),
+		},
+		{
+			// 22:00 UTC on the 14th is already the 15th in Istanbul (UTC+3).
+			"local date differs from UTC",
+			time.Date(2026, 3, 14, 22, 0, 0, 0, time.UTC),
+			time.Date(2026, 3, 15, 0, 0, 0, 0, ist), time.Date(2026, 4, 15, 0, 0, 0, 0, ist),
+		},
+		{
+			"across the new year",
+			time.Date(2027, 1, 3, 0, 0, 0, 0, ist),
+			time.Date(2026, 12, 15, 0, 0, 0, 0, ist), time.Date(2027, 1, 15, 0, 0, 0, 0, ist),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := s.seasonBounds(tt.at)
+			if !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("seasonBounds = %v, %v; want %v, %v", start, end, tt.start, tt.end)
+			}
+		})
+	}
+
+	for _, cfg := range []config.Config{
+		{SeasonTimezone: "Mars/Olympus", SeasonStartDay: 1},
+		{SeasonTimezone: "UTC", SeasonStartDay: 0},
+		{SeasonTimezone: "UTC", SeasonStartDay: 29},
+		{SeasonTimezone: "UTC", SeasonStartDay: 1, SeasonPrizes: "1:premium"},
+	} {
+		if _, err := NewProgressionService(nil, &cfg, nil, nil, nil); err == nil {
+			t.Errorf("NewProgressionService(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
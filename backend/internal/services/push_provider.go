@@ -0,0 +1,505 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// PushMessage is one push to one device token.
+type PushMessage struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// PushResult is the outcome for one message. Invalid means the provider
+// reported the token as no longer valid and it should be removed. TicketID
+// is set when the provider only queued the push and reports delivery later
+// through a receipt.
+type PushResult struct {
+	Token    string
+	Invalid  bool
+	Err      error
+	TicketID string
+}
+
+// PushProvider delivers pushes through one provider. Send returns one
+// result per message, in order; an error means the whole batch failed.
+type PushProvider interface {
+	Send(ctx context.Context, msgs []PushMessage) ([]PushResult, error)
+}
+
+// PushReceiptChecker is implemented by providers that issue tickets.
+// Receipts returns the results of the tickets whose receipts are ready,
+// keyed by ticket ID; Token is not set on them.
+type PushReceiptChecker interface {
+	Receipts(ctx context.Context, ticketIDs []string) (map[string]PushResult, error)
+}
+
+// NewPushProviders returns the providers keyed by the DeviceToken.Provider
+// they serve. PUSH_DRIVER selects the implementation: "live" for Expo Push
+// and APNs, "log" for providers that only log.
+func NewPushProviders(cfg *config.Config) (map[string]PushProvider, error) {
+	switch cfg.PushDriver {
+	case "live":
+		providers := map[string]PushProvider{"expo": NewExpoPushProvider(cfg)}
+		if cfg.APNsKeyFile != "" {
+			apns, err := NewAPNsPushProvider(cfg)
+			if err != nil {
+				return nil, err
+			}
+			providers["apns"] = apns
+		}
+		return providers, nil
+	case "", "log":
+		return map[string]PushProvider{
+			"expo": LogPushProvider{name: "expo"},
+			"apns": LogPushProvider{name: "apns"},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown PUSH_DRIVER %q", cfg.PushDriver)
+}
+
+// --- Expo Push ---
+
+const (
+	expoPushURL          = "https://exp.host/--/api/v2/push/send"
+	expoReceiptsURL      = "https://exp.host/--/api/v2/push/getReceipts"
+	expoBatchSize        = 100  // Expo's limit per request
+	expoReceiptBatchSize = 1000 // Expo's limit of receipt IDs per request
+)
+
+// ExpoPushProvider sends through the Expo Push API, which forwards to
+// APNs and FCM.
+type ExpoPushProvider struct {
+	client      *http.Client
+	url         string
+	receiptsURL string
+	accessToken string
+}
+
+func NewExpoPushProvider(cfg *config.Config) *ExpoPushProvider {
+	return &ExpoPushProvider{
+		client:      &http.Client{Timeout: 30 * time.Second},
+		url:         expoPushURL,
+		receiptsURL: expoReceiptsURL,
+		accessToken: cfg.ExpoAccessToken,
+	}
+}
+
+type expoMessage struct {
+	To    string            `json:"to"`
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+	Sound string            `json:"sound"`
+}
+
+// expoTicket is both a push ticket and a receipt; only tickets have an ID.
+type expoTicket struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"` // "ok" or "error"
+	Message string `json:"message"`
+	Details struct {
+		Error string `json:"error"` // e.g. "DeviceNotRegistered"
+	} `json:"details"`
+}
+
+func (p *ExpoPushProvider) Send(ctx context.Context, msgs []PushMessage) ([]PushResult, error) {
+	results := make([]PushResult, 0, len(msgs))
+	for start := 0; start < len(msgs); start += expoBatchSize {
+		end := min(start+expoBatchSize, len(msgs))
+		batch, err := p.sendBatch(ctx, msgs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batch...)
+	}
+	return results, nil
+}
+
+func (p *ExpoPushProvider) sendBatch(ctx context.Context, msgs []PushMessage) ([]PushResult, error) {
+	payload := make([]expoMessage, len(msgs))
+	for i, m := range msgs {
+		payload[i] = expoMessage{To: m.Token, Title: m.Title, Body: m.Body, Data: m.Data, Sound: "default"}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Data []expoTicket `json:"data"`
+	}
+	if err := p.post(ctx, p.url, body, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Data) != len(msgs) {
+		return nil, fmt.Errorf("expo push returned %d tickets for %d messages", len(out.Data), len(msgs))
+	}
+
+	results := make([]PushResult, len(msgs))
+	for i, ticket := range out.Data {
+		results[i] = ticket.result()
+		results[i].Token = msgs[i].Token
+	}
+	return results, nil
+}
+
+// Receipts fetches the receipts of earlier tickets. Expo has them ready
+// within about 15 minutes and keeps them for a day.
+func (p *ExpoPushProvider) Receipts(ctx context.Context, ticketIDs []string) (map[string]PushResult, error) {
+	results := make(map[string]PushResult, len(ticketIDs))
+	for start := 0; start < len(ticketIDs); start += expoReceiptBatchSize {
+		end := min(start+expoReceiptBatchSize, len(ticketIDs))
+		body, err := json.Marshal(map[string][]string{"ids": ticketIDs[start:end]})
+		if err != nil {
+			return nil, err
+		}
+
+		var out struct {
+			Data map[string]expoTicket `json:"data"`
+		}
+		if err := p.post(ctx, p.receiptsURL, body, &out); err != nil {
+			return nil, err
+		}
+		for id, receipt := range out.Data {
+			results[id] = receipt.result()
+		}
+	}
+	return results, nil
+}
+
+func (t *expoTicket) result() PushResult {
+	if t.Status == "error" {
+		return PushResult{
+			Err:     fmt.Errorf("expo: %s", t.Message),
+			Invalid: t.Details.Error == "DeviceNotRegistered",
+		}
+	}
+	return PushResult{TicketID: t.ID}
+}
+
+func (p *ExpoPushProvider) post(ctx context.Context, url string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if p.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.accessToken)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("expo push request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("expo push returned %d: %s", resp.StatusCode, msg)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode expo push response: %w", err)
+	}
+	return nil
+}
+
+// --- APNs ---
+
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+	apnsConcurrency    = 8
+	apnsTokenLifetime  = 50 * time.Minute // Apple rejects provider tokens older than an hour
+)
+
+// apnsInvalidReasons are the APNs error reasons that mean the device token
+// will never work again.
+var apnsInvalidReasons = map[string]bool{
+	"BadDeviceToken":         true,
+	"DeviceTokenNotForTopic": true,
+	"Unregistered":           true,
+}
+
+// APNsPushProvider sends directly to APNs over HTTP/2 with token-based
+// (.p8 key) authentication.
+type APNsPushProvider struct {
+	client *http.Client
+	host   string
+	topic  string
+	keyID  string
+	teamID string
+	key    *ecdsa.PrivateKey
+
+	mu        sync.Mutex
+	jwt       string
+	jwtIssued time.Time
+}
+
+func NewAPNsPushProvider(cfg *config.Config) (*APNsPushProvider, error) {
+	pem, err := os.ReadFile(cfg.APNsKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read APNS_KEY_FILE: %w", err)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNS_KEY_FILE: %w", err)
+	}
+	if cfg.APNsKeyID == "" || cfg.APNsTeamID == "" {
+		return nil, fmt.Errorf("APNS_KEY_ID and APNS_TEAM_ID are required with APNS_KEY_FILE")
+	}
+	topic := cfg.APNsTopic
+	if topic == "" {
+		topic = cfg.AppleBundleID
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("APNS_TOPIC or APPLE_BUNDLE_ID is required with APNS_KEY_FILE")
+	}
+
+	host := apnsSandboxHost
+	if cfg.APNsProduction {
+		host = apnsProductionHost
+	}
+
+	// The default transport negotiates HTTP/2 over TLS, which APNs requires
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+
+	return &APNsPushProvider{
+		client: &http.Client{Timeout: 15 * time.Second, Transport: transport},
+		host:   host,
+		topic:  topic,
+		keyID:  cfg.APNsKeyID,
+		teamID: cfg.APNsTeamID,
+		key:    key,
+	}, nil
+}
+
+func (p *APNsPushProvider) Send(ctx context.Context, msgs []PushMessage) ([]PushResult, error) {
+	bearer, err := p.providerToken("")
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PushResult, len(msgs))
+	sem := make(chan struct{}, apnsConcurrency)
+	var wg sync.WaitGroup
+	for i := range msgs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = p.sendOne(ctx, bearer, &msgs[i])
+		}(i)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+func (p *APNsPushProvider) sendOne(ctx context.Context, bearer string, m *PushMessage) PushResult {
+	result := PushResult{Token: m.Token}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": m.Title, "body": m.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range m.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	status, reason, err := p.post(ctx, bearer, m.Token, body)
+	if err == nil && status == http.StatusForbidden && reason == "ExpiredProviderToken" {
+		// APNs can reject a token before our own refresh deadline (e.g.
+		// clock skew); sign a new one and retry once
+		if bearer, err = p.providerToken(bearer); err == nil {
+			status, reason, err = p.post(ctx, bearer, m.Token, body)
+		}
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if status == http.StatusOK {
+		return result
+	}
+
+	result.Err = fmt.Errorf("apns returned %d: %s", status, reason)
+	result.Invalid = status == http.StatusGone || apnsInvalidReasons[reason]
+	return result
+}
+
+// post sends one notification and returns the HTTP status and, on
+// failure, the APNs reason.
+func (p *APNsPushProvider) post(ctx context.Context, bearer, token string, body []byte) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", p.host+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("authorization", "bearer "+bearer)
+	req.Header.Set("apns-topic", p.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("apns request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Reason string `json:"reason"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		json.NewDecoder(resp.Body).Decode(&out)
+	}
+	return resp.StatusCode, out.Reason, nil
+}
+
+// providerToken returns the signed JWT APNs authenticates requests with,
+// reusing it until it nears Apple's one-hour limit. Passing the token APNs
+// just rejected as expired forces a new one, unless a concurrent request
+// already replaced it.
+func (p *APNsPushProvider) providerToken(rejected string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.jwt != "" && p.jwt != rejected && time.Since(p.jwtIssued) < apnsTokenLifetime {
+		return p.jwt, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.keyID
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign APNs token: %w", err)
+	}
+	p.jwt, p.jwtIssued = signed, now
+	return signed, nil
+}
+
+// --- Log ---
+
+// LogPushProvider logs pushes instead of sending them, for development
+// servers. It keeps nothing and logs only the end of each token.
+type LogPushProvider struct {
+	name string
+}
+
+func (p LogPushProvider) Send(ctx context.Context, msgs []PushMessage) ([]PushResult, error) {
+	results := make([]PushResult, len(msgs))
+	for i, m := range msgs {
+		results[i] = PushResult{Token: m.Token}
+		token := m.Token
+		if len(token) > 6 {
+			token = "…" + token[len(token)-6:]
+		}
+		log.Printf("Push (%s) to %s: %s - %s", p.name, token, m.Title, m.Body)
+	}
+	return results, nil
+}
+
+// --- Fake ---
+
+// FakePushProvider records pushes for tests instead of sending them.
+// Tokens passed to MarkInvalid are reported as unregistered, on later
+// sends and in the receipts of earlier ones, which exercises token pruning
+// without a real provider.
+type FakePushProvider struct {
+	name string
+
+	mu      sync.Mutex
+	sent    []PushMessage
+	invalid map[string]bool
+	tickets map[string]string // ticket ID -> token
+}
+
+func NewFakePushProvider(name string) *FakePushProvider {
+	return &FakePushProvider{name: name, invalid: map[string]bool{}, tickets: map[string]string{}}
+}
+
+func (p *FakePushProvider) Send(ctx context.Context, msgs []PushMessage) ([]PushResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	results := make([]PushResult, len(msgs))
+	for i, m := range msgs {
+		results[i] = PushResult{Token: m.Token}
+		if p.invalid[m.Token] {
+			results[i].Invalid = true
+			results[i].Err = fmt.Errorf("%s: device not registered", p.name)
+			continue
+		}
+		p.sent = append(p.sent, m)
+		results[i].TicketID = uuid.NewString()
+		p.tickets[results[i].TicketID] = m.Token
+	}
+	return results, nil
+}
+
+// Receipts reports the tickets whose token has since been marked invalid
+// as unregistered and the rest as delivered.
+func (p *FakePushProvider) Receipts(ctx context.Context, ticketIDs []string) (map[string]PushResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	results := make(map[string]PushResult, len(ticketIDs))
+	for _, id := range ticketIDs {
+		token, ok := p.tickets[id]
+		if !ok {
+			continue
+		}
+		delete(p.tickets, id)
+		if p.invalid[token] {
+			results[id] = PushResult{Invalid: true, Err: fmt.Errorf("%s: device not registered", p.name)}
+		} else {
+			results[id] = PushResult{}
+		}
+	}
+	return results, nil
+}
+
+// MarkInvalid makes later sends to token and receipts of earlier sends to
+// it fail as unregistered.
+func (p *FakePushProvider) MarkInvalid(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalid[token] = true
+}
+
+// Sent returns the messages delivered so far.
+func (p *FakePushProvider) Sent() []PushMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PushMessage(nil), p.sent...)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAPNsRefreshesExpiredProviderToken(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var expired string
+	bearers := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer := strings.TrimPrefix(r.Header.Get("authorization"), "bearer ")
+		mu.Lock()
+		defer mu.Unlock()
+		bearers[bearer]++
+		if bearer == expired {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"reason":"ExpiredProviderToken"}`))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"reason":"Unregistered"}`))
+		}
+	}))
+	defer srv.Close()
+
+	p := &APNsPushProvider{client: srv.Client(), host: srv.URL, topic: "app.wouldyou.test", keyID: "KEY", teamID: "TEAM", key: key}
+	stale, err := p.providerToken("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// APNs considers the cached token expired although it is within our
+	// own lifetime
+	expired = stale
+
+	msgs := []PushMessage{{Token: "a"}, {Token: "b"}, {Token: "c"}, {Token: "gone"}}
+	results, err := p.Send(context.Background(), msgs)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	for _, r := range results[:3] {
+		if r.Err != nil || r.Invalid {
+			t.Errorf("result for %s = %+v, want delivered", r.Token, r)
+		}
+	}
+	if r := results[3]; r.Err == nil || !r.Invalid {
+		t.Errorf("result for gone = %+v, want invalid", r)
+	}
+
+	// Every request was retried with one shared replacement token
+	if len(bearers) != 2 || bearers[stale] != len(msgs) {
+		t.Errorf("bearers used = %v, want the stale token %d times and one replacement", bearers, len(msgs))
+	}
+	if p.jwt == stale || time.Since(p.jwtIssued) > time.Minute {
+		t.Error("provider token was not replaced")
+	}
+}
+
+func TestExpoTicketsAndReceipts(t *testing.T) {
+	var receiptIDs []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[
+			{"status":"ok","id":"t1"},
+			{"status":"error","message":"not registered","details":{"error":"DeviceNotRegistered"}},
+			{"status":"ok","id":"t3"}
+		]}`))
+	})
+	mux.HandleFunc("/receipts", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			IDs []string `json:"ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		receiptIDs = req.IDs
+		// t3's receipt isn't ready yet
+		w.Write([]byte(`{"data":{
+			"t1":{"status":"error","message":"gone","details":{"error":"DeviceNotRegistered"}},
+			"t2":{"status":"error","message":"too big","details":{"error":"MessageTooBig"}},
+			"t4":{"status":"ok"}
+		}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := &ExpoPushProvider{client: srv.Client(), url: srv.URL + "/send", receiptsURL: srv.URL + "/receipts"}
+
+	results, err := p.Send(context.Background(), []PushMessage{{Token: "a"}, {Token: "b"}, {Token: "c"}})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if r := results[0]; r.Token != "a" || r.TicketID != "t1" || r.Err != nil {
+		t.Errorf("results[0] = %+v, want ticket t1", r)
+	}
+	if r := results[1]; r.Token != "b" || r.TicketID != "" || !r.Invalid {
+		t.Errorf("results[1] = %+v, want invalid without a ticket", r)
+	}
+	if r := results[2]; r.Token != "c" || r.TicketID != "t3" {
+		t.Errorf("results[2] = %+v, want ticket t3", r)
+	}
+
+	receipts, err := p.Receipts(context.Background(), []string{"t1", "t2", "t3", "t4"})
+	if err != nil {
+		t.Fatalf("Receipts: %v", err)
+	}
+	if len(receiptIDs) != 4 {
+		t.Errorf("requested receipts for %v", receiptIDs)
+	}
+	if r, ok := receipts["t1"]; !ok || !r.Invalid {
+		t.Errorf("receipt t1 = %+v, want invalid", r)
+	}
+	if r, ok := receipts["t2"]; !ok || r.Invalid || r.Err == nil {
+		t.Errorf("receipt t2 = %+v, want a failure that keeps the token", r)
+	}
+	if _, ok := receipts["t3"]; ok {
+		t.Error("receipt t3 reported before it was ready")
+	}
+	if r, ok := receipts["t4"]; !ok || r.Err != nil {
+		t.Errorf("receipt t4 = %+v, want delivered", r)
+	}
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidDeviceToken      = errors.New("invalid device token")
+	ErrUnsupportedPushProvider = errors.New("unsupported push provider")
+	ErrInvalidTimezone         = errors.New("invalid timezone")
+	ErrDeviceNotFound          = errors.New("device not found")
+)
+
+const (
+	maxDevicesPerOwner = 10
+	// pushReceiptDelay is how long after sending receipts are fetched;
+	// Expo has them ready within about 15 minutes.
+	pushReceiptDelay = 15 * time.Minute
+	// pushTicketTTL is how long a ticket is kept while it has no receipt;
+	// Expo drops receipts after a day.
+	pushTicketTTL        = 24 * time.Hour
+	pushReceiptBatchSize = 1000
+)
+
+// PushService keeps the device token registry and sends pushes through the
+// configured providers. It is also a Notifier, so inbox notifications are
+// pushed to the user's devices.
+type PushService struct {
+	db        *gorm.DB
+	providers map[string]PushProvider
+	dailyHour int
+}
+
+func NewPushService(db *gorm.DB, cfg *config.Config, providers map[string]PushProvider) (*PushService, error) {
+	if cfg.DailyPushHour < 0 || cfg.DailyPushHour > 23 {
+		return nil, fmt.Errorf("DAILY_PUSH_HOUR must be between 0 and 23")
+	}
+	return &PushService{db: db, providers: providers, dailyHour: cfg.DailyPushHour}, nil
+}
+
+// RegisterDevice stores a push token for a user or guest. Registering a
+// token that is already known moves it to the caller and updates its
+// settings, so reinstalling or signing in never duplicates a device.
+func (s *PushService) RegisterDevice(userID uuid.UUID, guestID string, req *dto.RegisterDeviceRequest) (*dto.DeviceResponse, error) {
+	token := strings.TrimSpace(req.Token)
+	provider := strings.ToLower(strings.TrimSpace(req.Provider))
+	if _, ok := s.providers[provider]; !ok {
+		return nil, ErrUnsupportedPushProvider
+	}
+	if !validDeviceToken(provider, token) {
+		return nil, ErrInvalidDeviceToken
+	}
+
+	timezone := strings.TrimSpace(req.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+
+	platform := strings.ToLower(strings.TrimSpace(req.Platform))
+	if platform != "ios" && platform != "android" {
+		platform = ""
+	}
+
+	dailyReminders := true
+	if req.DailyReminders != nil {
+		dailyReminders = *req.DailyReminders
+	}
+	if userID != uuid.Nil {
+		guestID = ""
+	}
+
+	device := models.DeviceToken{
+		Token:          token,
+		Provider:       provider,
+		Platform:       platform,
+		UserID:         userID,
+		GuestID:        guestID,
+		Timezone:       timezone,
+		DailyReminders: dailyReminders,
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"provider", "platform", "user_id", "guest_id", "timezone", "daily_reminders", "updated_at"}),
+	}).Create(&device).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("token = ?", token).First(&device).Error; err != nil {
+		return nil, err
+	}
+
+	s.trimDevices(userID, guestID)
+
+	return toDeviceResponse(&device), nil
+}
+
+// UnregisterDevice removes one of the caller's tokens, e.g. on sign-out.
+func (s *PushService) UnregisterDevice(userID uuid.UUID, guestID, token string) error {
+	q := s.db.Where("token = ?", strings.TrimSpace(token))
+	if userID != uuid.Nil {
+		q = q.Where("user_id = ?", userID)
+	} else {
+		q = q.Where("user_id = ? AND guest_id = ?", uuid.Nil, guestID)
+	}
+
+	res := q.Delete(&models.DeviceToken{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+// Notify pushes a notification to every device of the user.
+func (s *PushService) Notify(userID uuid.UUID, n Notification) error {
+	if userID == uuid.Nil {
+		return nil
+	}
+
+	var devices []models.DeviceToken
+	if err := s.db.Where("user_id = ?", userID).Find(&devices).Error; err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+
+	data := map[string]string{"type": n.Type}
+	for k, v := range n.Data {
+		data[k] = v
+	}
+	_, err := s.send(context.Background(), devices, n.Title, n.Body, data)
+	return err
+}
+
+// SendDailyReminders pushes the current daily challenge to devices whose
+// reminder is due (see reminderStart) and that haven't had one for their
+// local date. Devices whose owner already answered it are skipped. Run it
+// more often than hourly so zones with half-hour offsets are caught.
+func (s *PushService) SendDailyReminders(ctx context.Context, challenge *models.Challenge) error {
+	var zones []string
+	if err := s.db.Model(&models.DeviceToken{}).
+		Where("daily_reminders = ?", true).
+		Distinct().Pluck("timezone", &zones).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, zone := range zones {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			continue
+		}
+		local := now.In(loc)
+		start := s.reminderStart(local)
+		if now.Before(start) || !now.Before(start.Add(time.Hour)) {
+			continue
+		}
+		if err := s.remindZone(ctx, zone, local.Format("2006-01-02"), challenge); err != nil {
+			log.Printf("Daily reminders for %s failed: %v", zone, err)
+		}
+	}
+	return nil
+}
+
+// reminderStart is when the reminder for local's date is due: the
+// configured hour in that zone, but never before the daily for that date
+// is live. Dailies roll over at midnight UTC, so zones far enough ahead of
+// UTC (e.g. 09:00 in UTC+13 is still yesterday in UTC) are reminded when
+// the UTC day catches up rather than pushed yesterday's question. The
+// reminder always carries the daily the app currently serves.
+func (s *PushService) reminderStart(local time.Time) time.Time {
+	year, month, day := local.Date()
+	start := time.Date(year, month, day, s.dailyHour, 0, 0, 0, local.Location())
+	if live := time.Date(year, month, day, 0, 0, 0, 0, time.UTC); live.After(start) {
+		return live
+	}
+	return start
+}
+
+func (s *PushService) remindZone(ctx context.Context, zone, localDate string, challenge *models.Challenge) error {
+	var devices []models.DeviceToken
+	if err := s.db.
+		Where("timezone = ? AND daily_reminders = ? AND (last_daily_push IS NULL OR last_daily_push <> ?)", zone, true, localDate).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM votes
+			WHERE votes.challenge_id = ? AND votes.deleted_at IS NULL
+			AND ((device_tokens.user_id <> ? AND votes.user_id = device_tokens.user_id)
+				OR (device_tokens.guest_id <> '' AND votes.guest_id = device_tokens.guest_id))
+		)`, challenge.ID, uuid.Nil).
+		Where("device_tokens.user_id = ? OR device_tokens.user_id IN (SELECT id FROM users WHERE deleted_at IS NULL AND deletion_requested_at IS NULL)", uuid.Nil).
+		Find(&devices).Error; err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+
+	// Mark before sending so an overlapping run can't remind twice
+	ids := make([]uuid.UUID, len(devices))
+	for i, d := range devices {
+		ids[i] = d.ID
+	}
+	if err := s.db.Model(&models.DeviceToken{}).Where("id IN ?", ids).
+		Update("last_daily_push", localDate).Error; err != nil {
+		return err
+	}
+
+	data := map[string]string{"type": "daily_live", "challenge_id": challenge.ID.String()}
+	sent, err := s.send(ctx, devices, "Today's question is live", fmt.Sprintf("Would you rather %s or %s?", lowerFirst(challenge.OptionA), lowerFirst(challenge.OptionB)), data)
+	if err != nil {
+		return err
+	}
+	log.Printf("Sent %d daily reminders in %s", sent, zone)
+	return nil
+}
+
+// send pushes one message to each device, grouped by provider, deletes
+// the tokens the providers report as invalid and keeps the tickets of
+// queued pushes for CheckReceipts. It returns how many pushes were
+// accepted.
+func (s *PushService) send(ctx context.Context, devices []models.DeviceToken, title, body string, data map[string]string) (int, error) {
+	byProvider := map[string][]PushMessage{}
+	for _, d := range devices {
+		byProvider[d.Provider] = append(byProvider[d.Provider], PushMessage{
+			Token: d.Token,
+			Title: title,
+			Body:  body,
+			Data:  data,
+		})
+	}
+
+	sent := 0
+	var invalid []string
+	var tickets []models.PushTicket
+	var firstErr error
+	for name, msgs := range byProvider {
+		provider, ok := s.providers[name]
+		if !ok {
+			continue
+		}
+		results, err := provider.Send(ctx, msgs)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for _, r := range results {
+			switch {
+			case r.Invalid:
+				invalid = append(invalid, r.Token)
+			case r.Err != nil:
+				log.Printf("Push to %s failed: %v", name, r.Err)
+			default:
+				sent++
+				if r.TicketID != "" {
+					tickets = append(tickets, models.PushTicket{ID: r.TicketID, Provider: name, Token: r.Token})
+				}
+			}
+		}
+	}
+
+	if len(tickets) > 0 {
+		if err := s.db.CreateInBatches(&tickets, pushReceiptBatchSize).Error; err != nil {
+			log.Printf("Failed to store %d push tickets: %v", len(tickets), err)
+		}
+	}
+	s.pruneTokens(invalid)
+	return sent, firstErr
+}
+
+// CheckReceipts fetches the receipts of pushes sent at least
+// pushReceiptDelay ago and deletes the tokens of devices that turned out
+// to be unregistered. Tickets are dropped once their receipt is read or
+// after pushTicketTTL.
+func (s *PushService) CheckReceipts(ctx context.Context) error {
+	now := time.Now()
+	if err := s.db.Where("created_at < ?", now.Add(-pushTicketTTL)).Delete(&models.PushTicket{}).Error; err != nil {
+		return err
+	}
+
+	for name, provider := range s.providers {
+		checker, ok := provider.(PushReceiptChecker)
+		if !ok {
+			continue
+		}
+
+		var tickets []models.PushTicket
+		err := s.db.Where("provider = ? AND created_at <= ?", name, now.Add(-pushReceiptDelay)).
+			FindInBatches(&tickets, pushReceiptBatchSize, func(tx *gorm.DB, batch int) error {
+				ids := make([]string, len(tickets))
+				for i, t := range tickets {
+					ids[i] = t.ID
+				}
+				receipts, err := checker.Receipts(ctx, ids)
+				if err != nil {
+					return err
+				}
+
+				var read, invalid []string
+				for _, t := range tickets {
+					r, ok := receipts[t.ID]
+					if !ok {
+						continue
+					}
+					read = append(read, t.ID)
+					switch {
+					case r.Invalid:
+						invalid = append(invalid, t.Token)
+					case r.Err != nil:
+						log.Printf("Push to %s failed: %v", name, r.Err)
+					}
+				}
+				if len(read) > 0 {
+					if err := s.db.Where("id IN ?", read).Delete(&models.PushTicket{}).Error; err != nil {
+						return err
+					}
+				}
+				s.pruneTokens(invalid)
+				return nil
+			}).Error
+		if err != nil {
+			return fmt.Errorf("%s receipts: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// pruneTokens deletes tokens a provider reported as no longer valid.
+func (s *PushService) pruneTokens(tokens []string) {
+	if len(tokens) == 0 {
+		return
+	}
+	if err := s.db.Where("token IN ?", tokens).Delete(&models.DeviceToken{}).Error; err != nil {
+		log.Printf("Failed to prune %d invalid push tokens: %v", len(tokens), err)
+	} else {
+		log.Printf("Pruned %d invalid push tokens", len(tokens))
+	}
+}
+
+// trimDevices keeps only the most recently registered devices of an owner.
+func (s *PushService) trimDevices(userID uuid.UUID, guestID string) {
+	q := s.db.Model(&models.DeviceToken{})
+	if userID != uuid.Nil {
+		q = q.Where("user_id = ?", userID)
+	} else {
+		q = q.Where("user_id = ? AND guest_id = ?", uuid.Nil, guestID)
+	}
+
+	var stale []uuid.UUID
+	q.Order("updated_at DESC").Offset(maxDevicesPerOwner).Pluck("id", &stale)
+	if len(stale) > 0 {
+		s.db.Where("id IN ?", stale).Delete(&models.DeviceToken{})
+	}
+}
+
+// validDeviceToken checks the token looks like one the provider issues:
+// "ExponentPushToken[...]" for Expo, a hex device token for APNs.
+func validDeviceToken(provider, token string) bool {
+	switch provider {
+	case "expo":
+		return (strings.HasPrefix(token, "ExponentPushToken[") || strings.HasPrefix(token, "ExpoPushToken[")) &&
+			strings.HasSuffix(token, "]") && len(token) <= 255
+	case "apns":
+		if len(token) < 64 || len(token) > 200 {
+			return false
+		}
+		_, err := hex.DecodeString(token)
+		return err == nil
+	}
+	return false
+}
+
+func toDeviceResponse(d *models.DeviceToken) *dto.DeviceResponse {
+	return &dto.DeviceResponse{
+		Token:          d.Token,
+		Provider:       d.Provider,
+		Platform:       d.Platform,
+		Timezone:       d.Timezone,
+		DailyReminders: d.DailyReminders,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
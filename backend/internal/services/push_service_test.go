@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// testPushService returns a PushService on the test database sending
+// through a fake Expo provider. Each test's devices live in a zone of
+// their own, so runs don't see each other's rows.
+func testPushService(t *testing.T) (*PushService, *FakePushProvider, string) {
+	t.Helper()
+	db := testDB(t)
+
+	fake := NewFakePushProvider("expo")
+	s, err := NewPushService(db, &config.Config{DailyPushHour: 9}, map[string]PushProvider{"expo": fake})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone := "Test/" + uuid.NewString()
+	t.Cleanup(func() {
+		db.Where("token IN (SELECT token FROM device_tokens WHERE timezone = ?)", zone).Delete(&models.PushTicket{})
+		db.Where("timezone = ?", zone).Delete(&models.DeviceToken{})
+	})
+	return s, fake, zone
+}
+
+func addTestDevice(t *testing.T, s *PushService, zone, guestID, lastDailyPush string) models.DeviceToken {
+	t.Helper()
+	device := models.DeviceToken{
+		Token:          "ExponentPushToken[" + uuid.NewString() + "]",
+		Provider:       "expo",
+		GuestID:        guestID,
+		Timezone:       zone,
+		DailyReminders: true,
+		LastDailyPush:  lastDailyPush,
+	}
+	if err := s.db.Create(&device).Error; err != nil {
+		t.Fatal(err)
+	}
+	return device
+}
+
+func deviceExists(t *testing.T, s *PushService, token string) bool {
+	t.Helper()
+	var count int64
+	if err := s.db.Model(&models.DeviceToken{}).Where("token = ?", token).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func sentTokens(fake *FakePushProvider) map[string]bool {
+	tokens := map[string]bool{}
+	for _, m := range fake.Sent() {
+		tokens[m.Token] = true
+	}
+	return tokens
+}
+
+func TestReminderStart(t *testing.T) {
+	s := &PushService{dailyHour: 9}
+	zone := func(name string) *time.Location {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return loc
+	}
+	istanbul, auckland, kiritimati, honolulu := zone("Europe/Istanbul"), zone("Pacific/Auckland"), zone("Pacific/Kiritimati"), zone("Pacific/Honolulu")
+
+	tests := []struct {
+		name  string
+		local time.Time
+		want  time.Time
+	}{
+		{"before the configured hour", time.Date(2026, 3, 10, 7, 0, 0, 0, istanbul), time.Date(2026, 3, 10, 9, 0, 0, 0, istanbul)},
+		{"zone behind UTC", time.Date(2026, 3, 10, 20, 0, 0, 0, honolulu), time.Date(2026, 3, 10, 9, 0, 0, 0, honolulu)},
+		// 09:00 NZDT (UTC+13) is 20:00 UTC the day before
+		{"zone far ahead of UTC", time.Date(2026, 1, 10, 9, 30, 0, 0, auckland), time.Date(2026, 1, 10, 13, 0, 0, 0, auckland)},
+		{"UTC+14", time.Date(2026, 1, 10, 6, 0, 0, 0, kiritimati), time.Date(2026, 1, 10, 14, 0, 0, 0, kiritimati)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.reminderStart(tt.local)
+			if !got.Equal(tt.want) {
+				t.Errorf("reminderStart(%v) = %v, want %v", tt.local, got.In(tt.local.Location()), tt.want)
+			}
+			// The daily live then is the one for the device's local date
+			if utcDate, localDate := got.UTC().Format("2006-01-02"), tt.local.Format("2006-01-02"); utcDate != localDate {
+				t.Errorf("reminder at %v falls on UTC date %s, want %s", got, utcDate, localDate)
+			}
+		})
+	}
+}
+
+func TestPushSendPrunesInvalidTokens(t *testing.T) {
+	s, fake, zone := testPushService(t)
+	live := addTestDevice(t, s, zone, "guest-"+uuid.NewString(), "")
+	gone := addTestDevice(t, s, zone, "guest-"+uuid.NewString(), "")
+	fake.MarkInvalid(gone.Token)
+
+	sent, err := s.send(context.Background(), []models.DeviceToken{live, gone}, "Title", "Body", nil)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if sent != 1 || !sentTokens(fake)[live.Token] {
+		t.Errorf("sent %d pushes (%v), want only the live device", sent, fake.Sent())
+	}
+	if deviceExists(t, s, gone.Token) {
+		t.Error("invalid token was not pruned")
+	}
+	if !deviceExists(t, s, live.Token) {
+		t.Error("live token was pruned")
+	}
+}
+
+func TestRemindZoneSkipsRemindedDevicesAndVoters(t *testing.T) {
+	s, fake, zone := testPushService(t)
+	challenge := models.Challenge{OptionA: "Fly", OptionB: "Be invisible", IsDaily: true}
+	if err := s.db.Create(&challenge).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		s.db.Unscoped().Where("challenge_id = ?", challenge.ID).Delete(&models.Vote{})
+		s.db.Unscoped().Delete(&challenge)
+	})
+
+	const today = "2026-03-10"
+	due := addTestDevice(t, s, zone, "guest-"+uuid.NewString(), "2026-03-09")
+	reminded := addTestDevice(t, s, zone, "guest-"+uuid.NewString(), today)
+	voter := addTestDevice(t, s, zone, "guest-"+uuid.NewString(), "")
+	if err := s.db.Create(&models.Vote{GuestID: voter.GuestID, ChallengeID: challenge.ID, Choice: "A"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.remindZone(context.Background(), zone, today, &challenge); err != nil {
+		t.Fatalf("remindZone: %v", err)
+	}
+	sent := sentTokens(fake)
+	if !sent[due.Token] {
+		t.Error("due device was not reminded")
+	}
+	if sent[reminded.Token] {
+		t.Error("device already reminded today was reminded again")
+	}
+	if sent[voter.Token] {
+		t.Error("device whose owner already voted was reminded")
+	}
+
+	var marked models.DeviceToken
+	s.db.Where("token = ?", due.Token).First(&marked)
+	if marked.LastDailyPush != today {
+		t.Errorf("LastDailyPush = %q, want %q", marked.LastDailyPush, today)
+	}
+
+	// A second run the same day reminds nobody
+	if err := s.remindZone(context.Background(), zone, today, &challenge); err != nil {
+		t.Fatalf("remindZone: %v", err)
+	}
+	if n := len(fake.Sent()); n != 1 {
+		t.Errorf("%d pushes after the second run, want 1", n)
+	}
+}
+
+func TestCheckReceiptsPrunesUnregisteredDevices(t *testing.T) {
+	s, fake, zone := testPushService(t)
+	kept := addTestDevice(t, s, zone, "guest-"+uuid.NewString(), "")
+	uninstalled := addTestDevice(t, s, zone, "guest-"+uuid.NewString(), "")
+
+	if _, err := s.send(context.Background(), []models.DeviceToken{kept, uninstalled}, "Title", "Body", nil); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	// Accepted, then the receipt says the app is gone
+	fake.MarkInvalid(uninstalled.Token)
+
+	tokens := []string{kept.Token, uninstalled.Token}
+	var tickets int64
+	s.db.Model(&models.PushTicket{}).Where("token IN ?", tokens).Count(&tickets)
+	if tickets != 2 {
+		t.Fatalf("stored %d tickets, want 2", tickets)
+	}
+
+	// Receipts aren't fetched before they are ready
+	if err := s.CheckReceipts(context.Background()); err != nil {
+		t.Fatalf("CheckReceipts: %v", err)
+	}
+	if !deviceExists(t, s, uninstalled.Token) {
+		t.Fatal("token pruned before its receipt was due")
+	}
+
+	s.db.Model(&models.PushTicket{}).Where("token IN ?", tokens).
+		Update("created_at", time.Now().Add(-pushReceiptDelay-time.Minute))
+	if err := s.CheckReceipts(context.Background()); err != nil {
+		t.Fatalf("CheckReceipts: %v", err)
+	}
+	if deviceExists(t, s, uninstalled.Token) {
+		t.Error("unregistered token was not pruned")
+	}
+	if !deviceExists(t, s, kept.Token) {
+		t.Error("delivered token was pruned")
+	}
+	s.db.Model(&models.PushTicket{}).Where("token IN ?", tokens).Count(&tickets)
+	if tickets != 0 {
+		t.Errorf("%d tickets left after their receipts were read", tickets)
+	}
+}
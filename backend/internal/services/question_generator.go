@@ -126,61 +126,12 @@ Return a JSON array with this exact format:
 
 Make sure each question is unique and creative. Return ONLY the JSON array, nothing else.`, count, category, category)
 
-	glmReq := GLMRequest{
-		Model: s.model,
-		Messages: []GLMMessage{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: userPrompt},
-		},
-		Temperature: 0.9,
-		MaxTokens:   4096,
-	}
-
-	reqBody, err := json.Marshal(glmReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", s.apiURL, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
-
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call GLM API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	content, err := s.Complete(systemPrompt, userPrompt, 0.9, 4096)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("GLM API error: status=%d, body=%s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("GLM API returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var glmResp GLMResponse
-	if err := json.Unmarshal(body, &glmResp); err != nil {
-		return nil, fmt.Errorf("failed to parse GLM response: %w", err)
-	}
-
-	var content string
-	if len(glmResp.Choices) > 0 {
-		content = glmResp.Choices[0].Message.Content
-	} else if len(glmResp.Content) > 0 {
-		content = glmResp.Content[0].Text
-	} else {
-		return nil, fmt.Errorf("no content in GLM response")
+		return nil, err
 	}
 
 	// Clean up the content (remove markdown code blocks if present)
-	content = strings.TrimSpace(content)
 	content = strings.TrimPrefix(content, "```json")
 	content = strings.TrimPrefix(content, "```")
 	content = strings.TrimSuffix(content, "```")
@@ -225,6 +176,69 @@ Make sure each question is unique and creative. Return ONLY the JSON array, noth
 	return challenges, nil
 }
 
+// Complete sends a single system + user prompt to the GLM API and returns
+// the trimmed reply text
+func (s *QuestionGeneratorService) Complete(systemPrompt, userPrompt string, temperature float64, maxTokens int) (string, error) {
+	if s.apiKey == "" {
+		return "", fmt.Errorf("GLM API key not configured")
+	}
+
+	glmReq := GLMRequest{
+		Model: s.model,
+		Messages: []GLMMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
+	}
+
+	reqBody, err := json.Marshal(glmReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", s.apiURL, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call GLM API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("GLM API error: status=%d, body=%s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("GLM API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var glmResp GLMResponse
+	if err := json.Unmarshal(body, &glmResp); err != nil {
+		return "", fmt.Errorf("failed to parse GLM response: %w", err)
+	}
+
+	var content string
+	if len(glmResp.Choices) > 0 {
+		content = glmResp.Choices[0].Message.Content
+	} else if len(glmResp.Content) > 0 {
+		content = glmResp.Content[0].Text
+	} else {
+		return "", fmt.Errorf("no content in GLM response")
+	}
+
+	return strings.TrimSpace(content), nil
+}
+
 // GenerateForAllCategories generates questions for all standard categories
 func (s *QuestionGeneratorService) GenerateForAllCategories(countPerCategory int) (map[string][]models.Challenge, error) {
 	categories := []string{"funny", "deep", "food", "adventure", "impossible", "would", "tech", "lifestyle"}
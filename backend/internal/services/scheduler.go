@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Scheduler runs periodic background jobs (export processing, cleanup,
+// purges) inside the server process. Each job runs once at start-up and
+// then on its interval; a run never overlaps the previous one.
+type Scheduler struct {
+	jobs   []scheduledJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+func NewScheduler() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{ctx: ctx, cancel: cancel}
+}
+
+// Every registers a job. Jobs must be registered before Start.
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, scheduledJob{name: name, interval: interval, run: run})
+}
+
+// Start launches every registered job in its own goroutine.
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job)
+	}
+}
+
+// Stop cancels running jobs and waits for them to return.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(job scheduledJob) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(job)
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(job scheduledJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Scheduled job %s panicked: %v", job.name, r)
+		}
+	}()
+
+	if err := job.run(s.ctx); err != nil && s.ctx.Err() == nil {
+		log.Printf("Scheduled job %s failed: %v", job.name, err)
+	}
+}
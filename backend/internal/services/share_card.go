@@ -0,0 +1,416 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var ErrInvalidCardFormat = errors.New("format must be story or link")
+
+const (
+	ShareCardStory = "story" // 1080x1920, Instagram/TikTok stories
+	ShareCardLink  = "link"  // 1200x630, Open Graph link previews
+
+	// A card is re-rendered at most this often while votes keep coming in;
+	// in between, the last render is served even if percentages moved.
+	shareCardRerenderInterval = time.Minute
+	maxCachedShareCards       = 512
+)
+
+var shareCardSizes = map[string]image.Point{
+	ShareCardStory: {X: 1080, Y: 1920},
+	ShareCardLink:  {X: 1200, Y: 630},
+}
+
+// Design-system colors (SPEC.md)
+var (
+	cardBackground    = hexColor("#0A0A12")
+	cardSurface       = hexColor("#1A1A2E")
+	cardSurfaceGlass  = hexColor("#2A2A4A")
+	cardChoiceA       = hexColor("#FF6B9D")
+	cardChoiceB       = hexColor("#00D4AA")
+	cardAccent        = hexColor("#FFE66D")
+	cardHazeMid       = hexColor("#C44DFF")
+	cardTextPrimary   = hexColor("#FFFFFF")
+	cardTextSecondary = hexColor("#B8B8D0")
+)
+
+// ShareCard is a rendered PNG and the time it was drawn.
+type ShareCard struct {
+	PNG        []byte
+	RenderedAt time.Time
+}
+
+type cachedShareCard struct {
+	hash string
+	card *ShareCard
+}
+
+// ShareCardRenderer draws share images with the bundled Go fonts. Renders
+// are cached by content hash, and each code/format pair is re-rendered at
+// most once per shareCardRerenderInterval.
+type ShareCardRenderer struct {
+	regular *opentype.Font
+	bold    *opentype.Font
+
+	mu     sync.Mutex
+	byHash map[string]*ShareCard
+	latest map[string]cachedShareCard // keyed by code + format
+	order  []string                   // byHash insertion order, for eviction
+}
+
+func NewShareCardRenderer() (*ShareCardRenderer, error) {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load regular font: %w", err)
+	}
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bold font: %w", err)
+	}
+
+	return &ShareCardRenderer{
+		regular: regular,
+		bold:    bold,
+		byHash:  map[string]*ShareCard{},
+		latest:  map[string]cachedShareCard{},
+	}, nil
+}
+
+// Render returns the card for a shared challenge in the given format.
+func (r *ShareCardRenderer) Render(shared *dto.SharedChallengeResponse, format string) (*ShareCard, error) {
+	size, ok := shareCardSizes[format]
+	if !ok {
+		return nil, ErrInvalidCardFormat
+	}
+
+	key := shared.Code + ":" + format
+	hash := shareCardHash(shared, format)
+
+	r.mu.Lock()
+	if last, ok := r.latest[key]; ok {
+		if last.hash == hash || time.Since(last.card.RenderedAt) < shareCardRerenderInterval {
+			r.mu.Unlock()
+			return last.card, nil
+		}
+	}
+	if card, ok := r.byHash[hash]; ok {
+		r.latest[key] = cachedShareCard{hash: hash, card: card}
+		r.mu.Unlock()
+		return card, nil
+	}
+	r.mu.Unlock()
+
+	data, err := r.draw(shared, size)
+	if err != nil {
+		return nil, err
+	}
+	card := &ShareCard{PNG: data, RenderedAt: time.Now().UTC()}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byHash[hash]; !ok {
+		r.order = append(r.order, hash)
+		for len(r.order) > maxCachedShareCards {
+			delete(r.byHash, r.order[0])
+			r.order = r.order[1:]
+		}
+	}
+	r.byHash[hash] = card
+	r.latest[key] = cachedShareCard{hash: hash, card: card}
+	if len(r.latest) > maxCachedShareCards*2 {
+		// Forget throttle state for other cards rather than grow unbounded
+		r.latest = map[string]cachedShareCard{key: r.latest[key]}
+	}
+	return card, nil
+}
+
+func (r *ShareCardRenderer) draw(shared *dto.SharedChallengeResponse, size image.Point) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+	portrait := size.Y > size.X
+	unit := float64(size.X) / 1080
+	if !portrait {
+		unit = float64(size.Y) / 630
+	}
+	px := func(v float64) int { return int(v * unit) }
+
+	// Background: deep vertical gradient with a soft brand haze at the top
+	for y := 0; y < size.Y; y++ {
+		t := float64(y) / float64(size.Y)
+		draw.Draw(img, image.Rect(0, y, size.X, y+1), image.NewUniform(mixColor(cardBackground, cardSurface, t)), image.Point{}, draw.Src)
+	}
+	glow(img, image.Pt(size.X/5, 0), size.X/2, cardChoiceA, 0.22)
+	glow(img, image.Pt(size.X/2, 0), size.X/2, cardHazeMid, 0.14)
+	glow(img, image.Pt(size.X*4/5, 0), size.X/2, cardChoiceB, 0.22)
+
+	pad := px(64)
+	if !portrait {
+		pad = px(48)
+	}
+
+	// Header
+	titleSize := 64.0
+	if !portrait {
+		titleSize = 44
+	}
+	title, err := r.face(r.bold, titleSize*unit)
+	if err != nil {
+		return nil, err
+	}
+	defer title.Close()
+	headerTop := pad
+	if portrait {
+		headerTop = px(180)
+	}
+	drawCentered(img, title, "WOULD YOU RATHER?", size.X/2, headerTop+title.Metrics().Ascent.Ceil(), cardAccent)
+	headerBottom := headerTop + title.Metrics().Height.Ceil()
+
+	// Footer
+	footerSize := 34.0
+	if !portrait {
+		footerSize = 24
+	}
+	footer, err := r.face(r.regular, footerSize*unit)
+	if err != nil {
+		return nil, err
+	}
+	defer footer.Close()
+	footerText := fmt.Sprintf("%d votes  ·  %s", shared.TotalVotes, strings.TrimPrefix(strings.TrimPrefix(shared.URL, "https://"), "http://"))
+	footerBaseline := size.Y - pad
+	if portrait {
+		footerBaseline = size.Y - px(160)
+	}
+	drawCentered(img, footer, footerText, size.X/2, footerBaseline, cardTextSecondary)
+	footerTop := footerBaseline - footer.Metrics().Ascent.Ceil()
+
+	// Options
+	gap := px(40)
+	area := image.Rect(pad, headerBottom+px(48), size.X-pad, footerTop-px(48))
+	if !portrait {
+		area = image.Rect(pad, headerBottom+px(28), size.X-pad, footerTop-px(24))
+	}
+
+	var rectA, rectB image.Rectangle
+	if portrait {
+		mid := (area.Min.Y + area.Max.Y) / 2
+		rectA = image.Rect(area.Min.X, area.Min.Y, area.Max.X, mid-gap/2)
+		rectB = image.Rect(area.Min.X, mid+gap/2, area.Max.X, area.Max.Y)
+	} else {
+		mid := (area.Min.X + area.Max.X) / 2
+		rectA = image.Rect(area.Min.X, area.Min.Y, mid-gap/2, area.Max.Y)
+		rectB = image.Rect(mid+gap/2, area.Min.Y, area.Max.X, area.Max.Y)
+	}
+
+	if err := r.drawOption(img, rectA, unit, shared.OptionA, shared.PercentA, cardChoiceA, shared.SharerChoice == "A"); err != nil {
+		return nil, err
+	}
+	if err := r.drawOption(img, rectB, unit, shared.OptionB, shared.PercentB, cardChoiceB, shared.SharerChoice == "B"); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode share card: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawOption draws one choice: a glass card with the option text, the
+// global percentage bar and, for the sharer's pick, an accent outline and
+// badge.
+func (r *ShareCardRenderer) drawOption(img *image.RGBA, rect image.Rectangle, unit float64, text string, percent int, accent color.RGBA, picked bool) error {
+	px := func(v float64) int { return int(v * unit) }
+	radius := px(36)
+
+	if picked {
+		fillRoundedRect(img, rect.Inset(-px(6)), radius+px(6), cardAccent)
+	}
+	fillRoundedRect(img, rect, radius, cardSurfaceGlass)
+	fillRoundedRect(img, image.Rect(rect.Min.X+px(20), rect.Min.Y+radius, rect.Min.X+px(34), rect.Max.Y-radius), px(7), accent)
+
+	inner := rect.Inset(px(40))
+	inner.Min.X += px(20)
+
+	// Percentage bar along the bottom
+	label, err := r.face(r.bold, 52*unit)
+	if err != nil {
+		return err
+	}
+	defer label.Close()
+	barHeight := px(28)
+	percentText := fmt.Sprintf("%d%%", percent)
+	labelWidth := font.MeasureString(label, percentText).Ceil()
+	barTop := inner.Max.Y - barHeight
+	bar := image.Rect(inner.Min.X, barTop, inner.Max.X-labelWidth-px(24), barTop+barHeight)
+	fillRoundedRect(img, bar, barHeight/2, cardSurface)
+	if percent > 0 {
+		filled := bar
+		filled.Max.X = bar.Min.X + max(barHeight, bar.Dx()*min(percent, 100)/100)
+		fillRoundedRect(img, filled, barHeight/2, accent)
+	}
+	drawText(img, label, percentText, inner.Max.X-labelWidth, barTop+barHeight/2+label.Metrics().Ascent.Ceil()/2-px(4), accent)
+
+	// Badge for the sharer's pick
+	textTop := inner.Min.Y
+	if picked {
+		badge, err := r.face(r.bold, 30*unit)
+		if err != nil {
+			return err
+		}
+		defer badge.Close()
+		badgeText := "MY PICK"
+		badgeWidth := font.MeasureString(badge, badgeText).Ceil()
+		badgeRect := image.Rect(inner.Min.X, inner.Min.Y, inner.Min.X+badgeWidth+px(40), inner.Min.Y+px(56))
+		fillRoundedRect(img, badgeRect, badgeRect.Dy()/2, cardAccent)
+		drawText(img, badge, badgeText, badgeRect.Min.X+px(20), badgeRect.Min.Y+badgeRect.Dy()/2+badge.Metrics().Ascent.Ceil()/2-px(2), cardBackground)
+		textTop = badgeRect.Max.Y + px(20)
+	}
+
+	// Option text, shrunk until it fits above the bar
+	textArea := image.Rect(inner.Min.X, textTop, inner.Max.X, barTop-px(24))
+	for _, size := range []float64{72, 64, 56, 48, 42, 36, 30, 26} {
+		face, err := r.face(r.bold, size*unit)
+		if err != nil {
+			return err
+		}
+		lineHeight := face.Metrics().Height.Ceil() * 11 / 10
+		lines := wrapText(face, text, textArea.Dx())
+		maxLines := max(textArea.Dy()/lineHeight, 1)
+		if len(lines) <= maxLines || size == 26 {
+			if len(lines) > maxLines {
+				lines = lines[:maxLines]
+				lines[maxLines-1] = strings.TrimRight(lines[maxLines-1], " .,") + "…"
+			}
+			y := textArea.Min.Y + (textArea.Dy()-len(lines)*lineHeight)/2 + face.Metrics().Ascent.Ceil()
+			for _, line := range lines {
+				drawText(img, face, line, textArea.Min.X, y, cardTextPrimary)
+				y += lineHeight
+			}
+			face.Close()
+			return nil
+		}
+		face.Close()
+	}
+	return nil
+}
+
+func (r *ShareCardRenderer) face(f *opentype.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// shareCardHash identifies everything that ends up on the image.
+func shareCardHash(shared *dto.SharedChallengeResponse, format string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		format, shared.URL, shared.OptionA, shared.OptionB, shared.SharerChoice,
+		fmt.Sprint(shared.PercentA), fmt.Sprint(shared.PercentB), fmt.Sprint(shared.TotalVotes),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// --- Drawing helpers ---
+
+func wrapText(face font.Face, text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && font.MeasureString(face, candidate).Ceil() > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func drawText(img *image.RGBA, face font.Face, text string, x, baseline int, c color.Color) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, baseline),
+	}
+	d.DrawString(text)
+}
+
+func drawCentered(img *image.RGBA, face font.Face, text string, centerX, baseline int, c color.Color) {
+	width := font.MeasureString(face, text).Ceil()
+	drawText(img, face, text, centerX-width/2, baseline, c)
+}
+
+func fillRoundedRect(img *image.RGBA, rect image.Rectangle, radius int, c color.RGBA) {
+	rect = rect.Intersect(img.Bounds())
+	radius = min(radius, rect.Dx()/2, rect.Dy()/2)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			cx, cy := x, y
+			switch {
+			case x < rect.Min.X+radius:
+				cx = rect.Min.X + radius
+			case x >= rect.Max.X-radius:
+				cx = rect.Max.X - radius - 1
+			}
+			switch {
+			case y < rect.Min.Y+radius:
+				cy = rect.Min.Y + radius
+			case y >= rect.Max.Y-radius:
+				cy = rect.Max.Y - radius - 1
+			}
+			dx, dy := x-cx, y-cy
+			if dx*dx+dy*dy <= radius*radius {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+// glow blends a radial falloff of c around center into img.
+func glow(img *image.RGBA, center image.Point, radius int, c color.RGBA, strength float64) {
+	bounds := image.Rect(center.X-radius, center.Y-radius, center.X+radius, center.Y+radius).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			dx, dy := float64(x-center.X), float64(y-center.Y)
+			d := (dx*dx + dy*dy) / float64(radius*radius)
+			if d >= 1 {
+				continue
+			}
+			img.SetRGBA(x, y, mixColor(img.RGBAAt(x, y), c, strength*(1-d)*(1-d)))
+		}
+	}
+}
+
+func mixColor(a, b color.RGBA, t float64) color.RGBA {
+	lerp := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t) }
+	return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: 255}
+}
+
+func hexColor(hex string) color.RGBA {
+	var r, g, b uint8
+	fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b)
+	return color.RGBA{R: r, G: g, B: b, A: 255}
+}
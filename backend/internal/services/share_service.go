@@ -0,0 +1,349 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrShareNotFound     = errors.New("share not found")
+	ErrInvalidPlatform   = errors.New("invalid platform")
+	ErrInvalidShareGroup = errors.New("group_by must be challenge, platform or sharer")
+)
+
+// shareGroupColumns maps an analytics group_by value to its SQL key for
+// share events and for installs. Events carry their own platform (opens
+// without a ?via= count as "unknown"); an install only knows its share, so
+// it counts for the share's first platform.
+var shareGroupColumns = map[string]struct{ event, install string }{
+	"challenge": {"shares.challenge_id::text", "shares.challenge_id::text"},
+	"platform":  {"COALESCE(NULLIF(share_events.platform, ''), 'unknown')", "shares.platform"},
+	"sharer":    {"shares.user_id::text", "shares.user_id::text"},
+}
+
+var sharePlatforms = map[string]bool{
+	"instagram": true,
+	"tiktok":    true,
+	"imessage":  true,
+	"whatsapp":  true,
+	"x":         true,
+	"other":     true,
+}
+
+const (
+	shareCodeLength = 8
+	// Unambiguous characters only: no 0/O, 1/l/I
+	shareCodeAlphabet = "23456789abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+)
+
+type ShareService struct {
+	db           *gorm.DB
+	baseURL      string
+	renderer     *ShareCardRenderer
+	achievements *AchievementService
+	progression  *ProgressionService
+}
+
+func NewShareService(db *gorm.DB, cfg *config.Config, renderer *ShareCardRenderer, achievements *AchievementService, progression *ProgressionService) *ShareService {
+	return &ShareService{db: db, baseURL: cfg.PublicBaseURL, renderer: renderer, achievements: achievements, progression: progression}
+}
+
+// ShareURL is the public link for a share code.
+func (s *ShareService) ShareURL(code string) string {
+	return fmt.Sprintf("%s/s/%s", s.baseURL, code)
+}
+
+// CreateShare returns the user's share code for a challenge, creating it on
+// first share, and records a "shared" event for the platform.
+func (s *ShareService) CreateShare(userID uuid.UUID, req *dto.CreateShareRequest) (*dto.ShareResponse, error) {
+	platform := strings.ToLower(strings.TrimSpace(req.Platform))
+	if platform == "" {
+		platform = "other"
+	}
+	if !sharePlatforms[platform] {
+		return nil, ErrInvalidPlatform
+	}
+
+	var count int64
+	s.db.Model(&models.Challenge{}).Where("id = ?", req.ChallengeID).Count(&count)
+	if count == 0 {
+		return nil, ErrChallengeNotFound
+	}
+
+	var share models.Share
+	err := s.db.Where("user_id = ? AND challenge_id = ?", userID, req.ChallengeID).First(&share).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		share, err = s.insertShare(userID, req.ChallengeID, platform)
+		if err == nil {
+			go s.achievements.Record(userID, EventShareCreated)
+			go s.progression.AwardShare(userID, req.ChallengeID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(share.ID, "shared", platform)
+
+	return &dto.ShareResponse{
+		Code:        share.Code,
+		URL:         s.ShareURL(share.Code),
+		Platform:    platform,
+		ChallengeID: share.ChallengeID,
+	}, nil
+}
+
+// insertShare creates a share with a fresh code. A concurrent request for
+// the same user and challenge wins the unique index, so fall back to its row.
+func (s *ShareService) insertShare(userID, challengeID uuid.UUID, platform string) (models.Share, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := newShareCode()
+		if err != nil {
+			return models.Share{}, err
+		}
+
+		share := models.Share{
+			ID:          uuid.New(),
+			Code:        code,
+			UserID:      userID,
+			ChallengeID: challengeID,
+			Platform:    platform,
+		}
+		if err := s.db.Create(&share).Error; err == nil {
+			return share, nil
+		}
+
+		var existing models.Share
+		if s.db.Where("user_id = ? AND challenge_id = ?", userID, challengeID).First(&existing).Error == nil {
+			return existing, nil
+		}
+		// Otherwise the code collided; try another one
+	}
+	return models.Share{}, errors.New("failed to allocate share code")
+}
+
+// GetSharedChallenge resolves a public share code to the challenge, the
+// sharer's pick and the current global split, recording an "opened" event.
+func (s *ShareService) GetSharedChallenge(code, platform string) (*dto.SharedChallengeResponse, error) {
+	share, err := s.findShare(code)
+	if err != nil {
+		return nil, err
+	}
+
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	if !sharePlatforms[platform] {
+		platform = ""
+	}
+	s.recordEvent(share.ID, "opened", platform)
+
+	return s.sharedChallenge(share), nil
+}
+
+// RenderCard returns the share image for a code in the given format
+// (ShareCardStory or ShareCardLink). Fetching the image is not counted as
+// an open.
+func (s *ShareService) RenderCard(code, format string) (*ShareCard, error) {
+	share, err := s.findShare(code)
+	if err != nil {
+		return nil, err
+	}
+	return s.renderer.Render(s.sharedChallenge(share), format)
+}
+
+// LookupSharedChallenge is GetSharedChallenge without recording an open,
+// for machine fetches such as oEmbed.
+func (s *ShareService) LookupSharedChallenge(code string) (*dto.SharedChallengeResponse, error) {
+	share, err := s.findShare(code)
+	if err != nil {
+		return nil, err
+	}
+	return s.sharedChallenge(share), nil
+}
+
+func (s *ShareService) findShare(code string) (*models.Share, error) {
+	var share models.Share
+	if err := s.db.Joins("Challenge").Where("shares.code = ?", code).First(&share).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, err
+	}
+	if share.Challenge.ID == uuid.Nil {
+		// Challenge was removed (e.g. by moderation)
+		return nil, ErrShareNotFound
+	}
+	return &share, nil
+}
+
+func (s *ShareService) sharedChallenge(share *models.Share) *dto.SharedChallengeResponse {
+	var vote models.Vote
+	choice := ""
+	if s.db.Where("user_id = ? AND challenge_id = ?", share.UserID, share.ChallengeID).First(&vote).Error == nil {
+		choice = vote.Choice
+	}
+
+	ch := share.Challenge
+	percentA, percentB := votePercentages(ch.VotesA, ch.VotesB)
+
+	return &dto.SharedChallengeResponse{
+		Code:         share.Code,
+		URL:          s.ShareURL(share.Code),
+		ChallengeID:  ch.ID,
+		OptionA:      ch.OptionA,
+		OptionB:      ch.OptionB,
+		Category:     ch.Category,
+		SharerChoice: choice,
+		PercentA:     percentA,
+		PercentB:     percentB,
+		TotalVotes:   ch.VotesA + ch.VotesB,
+	}
+}
+
+func (s *ShareService) recordEvent(shareID uuid.UUID, eventType, platform string) {
+	s.db.Create(&models.ShareEvent{
+		ID:       uuid.New(),
+		ShareID:  shareID,
+		Type:     eventType,
+		Platform: platform,
+	})
+}
+
+// --- Admin analytics ---
+
+type shareGroupCount struct {
+	GroupKey  string
+	Count     int64
+	Activated int64
+}
+
+// ShareAnalytics reports shares, opens, installs and activated voters
+// between from and to, grouped by "challenge", "platform" or "sharer".
+// By platform, shares and opens count where each event happened, while
+// installs count for the platform the share link was first sent to.
+func (s *ShareService) ShareAnalytics(groupBy string, from, to time.Time, limit int) ([]dto.ShareAnalyticsRow, error) {
+	columns, ok := shareGroupColumns[groupBy]
+	if !ok {
+		return nil, ErrInvalidShareGroup
+	}
+
+	rows := map[string]*dto.ShareAnalyticsRow{}
+	row := func(key string) *dto.ShareAnalyticsRow {
+		if rows[key] == nil {
+			rows[key] = &dto.ShareAnalyticsRow{Key: key}
+		}
+		return rows[key]
+	}
+
+	for _, eventType := range []string{"shared", "opened"} {
+		var counts []shareGroupCount
+		if err := s.db.Table("share_events").
+			Select(columns.event+" AS group_key, COUNT(*) AS count").
+			Joins("JOIN shares ON shares.id = share_events.share_id").
+			Where("share_events.type = ? AND share_events.created_at >= ? AND share_events.created_at < ?", eventType, from, to).
+			Group("group_key").
+			Scan(&counts).Error; err != nil {
+			return nil, err
+		}
+		for _, c := range counts {
+			if eventType == "shared" {
+				row(c.GroupKey).Shares = c.Count
+			} else {
+				row(c.GroupKey).Opens = c.Count
+			}
+		}
+	}
+
+	// Installs outlive purged accounts, which record whether they had voted
+	var installs []shareGroupCount
+	if err := s.db.Table("share_installs").
+		Select(columns.install+" AS group_key, COUNT(*) AS count, "+
+			"COUNT(*) FILTER (WHERE share_installs.activated OR (share_installs.user_id <> ? AND EXISTS (SELECT 1 FROM votes WHERE votes.user_id = share_installs.user_id))) AS activated", uuid.Nil).
+		Joins("JOIN shares ON shares.id = share_installs.share_id").
+		Where("share_installs.created_at >= ? AND share_installs.created_at < ?", from, to).
+		Group("group_key").
+		Scan(&installs).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range installs {
+		r := row(c.GroupKey)
+		r.Installs = c.Count
+		r.ActivatedVoters = c.Activated
+	}
+
+	result := make([]dto.ShareAnalyticsRow, 0, len(rows))
+	for _, r := range rows {
+		if r.Opens > 0 {
+			r.InstallRate = float64(r.Installs) / float64(r.Opens)
+		}
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Installs != result[j].Installs {
+			return result[i].Installs > result[j].Installs
+		}
+		if result[i].Opens != result[j].Opens {
+			return result[i].Opens > result[j].Opens
+		}
+		return result[i].Key < result[j].Key
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+
+	s.labelAnalytics(groupBy, result)
+	return result, nil
+}
+
+// labelAnalytics fills in human-readable labels for challenge and sharer keys.
+func (s *ShareService) labelAnalytics(groupBy string, rows []dto.ShareAnalyticsRow) {
+	keys := make([]string, len(rows))
+	for i, r := range rows {
+		keys[i] = r.Key
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	labels := map[string]string{}
+	switch groupBy {
+	case "challenge":
+		var challenges []models.Challenge
+		s.db.Unscoped().Where("id IN ?", keys).Find(&challenges)
+		for _, ch := range challenges {
+			labels[ch.ID.String()] = ch.OptionA + " / " + ch.OptionB
+		}
+	case "sharer":
+		var users []models.User
+		s.db.Unscoped().Where("id IN ?", keys).Find(&users)
+		for _, u := range users {
+			labels[u.ID.String()] = u.Email
+		}
+	default:
+		return
+	}
+
+	for i := range rows {
+		rows[i].Label = labels[rows[i].Key]
+	}
+}
+
+func newShareCode() (string, error) {
+	buf := make([]byte, shareCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = shareCodeAlphabet[int(b)%len(shareCodeAlphabet)]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// statsRebuildInterval re-judges old votes against settled results;
+	// between rebuilds only new votes are folded in.
+	statsRebuildInterval = 7 * 24 * time.Hour
+	statsBatchSize       = 1000
+
+	// A challenge needs this many other voters before siding with the
+	// majority (or not) says anything about the user
+	minVotesForMajority = 10
+	maxContrarianPicks  = 5
+	// Picks shared by at most this share of other voters count as contrarian
+	contrarianThreshold = 35
+	// Badges need this many decided votes
+	minDecidedForBadge = 10
+)
+
+var personalityBadges = map[string]dto.PersonalityBadge{
+	"newcomer":      {ID: "newcomer", Name: "Newcomer", Description: "Vote on a few more challenges to reveal your style."},
+	"crowd_pleaser": {ID: "crowd_pleaser", Name: "Crowd Pleaser", Description: "You usually side with the majority."},
+	"wild_card":     {ID: "wild_card", Name: "Wild Card", Description: "Nobody can predict which way you'll go."},
+	"rebel":         {ID: "rebel", Name: "Rebel", Description: "You love picking the road less traveled."},
+}
+
+// statsVote is a vote joined with the challenge fields stats need.
+type statsVote struct {
+	ID          uuid.UUID
+	ChallengeID uuid.UUID
+	Choice      string
+	CreatedAt   time.Time
+	Category    string
+	IsDaily     bool
+	VotesA      int
+	VotesB      int
+}
+
+type StatsService struct {
+	db *gorm.DB
+}
+
+func NewStatsService(db *gorm.DB) *StatsService {
+	return &StatsService{db: db}
+}
+
+// GetUserStats returns the user's detailed stats.
+func (s *StatsService) GetUserStats(userID uuid.UUID) (*dto.UserStatsResponse, error) {
+	stats, err := s.UserStats(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(stats), nil
+}
+
+// UserStats returns the user's cached stats row. It is served as is unless
+// it was marked dirty or is due for a rebuild.
+func (s *StatsService) UserStats(userID uuid.UUID) (*models.UserStats, error) {
+	var stats models.UserStats
+
+	err := s.db.Where("user_id = ?", userID).First(&stats).Error
+	if err == nil && !stats.Dirty && time.Since(stats.RebuiltAt) <= statsRebuildInterval {
+		return &stats, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserStats{UserID: userID}).Error; err != nil {
+			return err
+		}
+		// The row lock also holds back markStatsDirty until we've saved, so
+		// a vote landing mid-fold leaves the row dirty for the next read
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).First(&stats).Error; err != nil {
+			return err
+		}
+
+		if time.Since(stats.RebuiltAt) > statsRebuildInterval {
+			resetStats(&stats)
+		}
+		if err := s.foldNewVotes(tx, &stats); err != nil {
+			return err
+		}
+
+		stats.Dirty = false
+		return tx.Save(&stats).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// markStatsDirty makes the next GetUserStats fold in the user's new votes.
+// rebuild discards the cache instead, for votes that can land behind the
+// watermark: backfilled offline votes and merged guest votes.
+func markStatsDirty(db *gorm.DB, userID uuid.UUID, rebuild bool) {
+	updates := map[string]interface{}{"dirty": true}
+	if rebuild {
+		updates["rebuilt_at"] = time.Time{}
+	}
+	if err := db.Model(&models.UserStats{}).Where("user_id = ?", userID).Updates(updates).Error; err != nil {
+		log.Printf("Failed to mark stats dirty for user %s: %v", userID, err)
+	}
+}
+
+func resetStats(stats *models.UserStats) {
+	stats.TotalVotes = 0
+	stats.CategoryCounts = map[string]int{}
+	stats.DecidedVotes = 0
+	stats.MajorityVotes = 0
+	stats.ContrarianPicks = nil
+	stats.DailyVotes = 0
+	stats.MinorityRun = 0
+	stats.LastVoteAt = time.Time{}
+	stats.LastVoteID = uuid.Nil
+	stats.RebuiltAt = time.Now()
+}
+
+// foldNewVotes applies every vote after the watermark, in (created_at, id)
+// order, and advances the watermark.
+func (s *StatsService) foldNewVotes(tx *gorm.DB, stats *models.UserStats) error {
+	if stats.CategoryCounts == nil {
+		stats.CategoryCounts = map[string]int{}
+	}
+
+	for {
+		var votes []statsVote
+		if err := tx.Table("votes").
+			Select("votes.id, votes.challenge_id, votes.choice, votes.created_at, challenges.category, challenges.is_daily, challenges.votes_a, challenges.votes_b").
+			Joins("JOIN challenges ON challenges.id = votes.challenge_id").
+			Where("votes.user_id = ? AND votes.deleted_at IS NULL", stats.UserID).
+			Where("(votes.created_at > ? OR (votes.created_at = ? AND votes.id > ?))", stats.LastVoteAt, stats.LastVoteAt, stats.LastVoteID).
+			Order("votes.created_at ASC, votes.id ASC").
+			Limit(statsBatchSize).
+			Scan(&votes).Error; err != nil {
+			return err
+		}
+
+		for _, v := range votes {
+			applyStatsVote(stats, &v)
+		}
+		if len(votes) < statsBatchSize {
+			return nil
+		}
+	}
+}
+
+func applyStatsVote(stats *models.UserStats, v *statsVote) {
+	stats.TotalVotes++
+	category := v.Category
+	if category == "" {
+		category = "general"
+	}
+	stats.CategoryCounts[category]++
+	if v.IsDaily {
+		stats.DailyVotes++
+	}
+	stats.LastVoteAt = v.CreatedAt
+	stats.LastVoteID = v.ID
+
+	// Judge against everyone else's votes, not including the user's own
+	sameSide, otherSide := v.VotesA, v.VotesB
+	if v.Choice == "B" {
+		sameSide, otherSide = v.VotesB, v.VotesA
+	}
+	sameSide = max(sameSide-1, 0)
+
+	// Ties break a minority run
+	if sameSide < otherSide {
+		stats.MinorityRun++
+	} else {
+		stats.MinorityRun = 0
+	}
+	others := sameSide + otherSide
+	if others < minVotesForMajority || sameSide == otherSide {
+		return
+	}
+
+	stats.DecidedVotes++
+	if sameSide > otherSide {
+		stats.MajorityVotes++
+	}
+
+	share := sameSide * 100 / others
+	if share > contrarianThreshold {
+		return
+	}
+	stats.ContrarianPicks = append(stats.ContrarianPicks, models.ContrarianPick{
+		ChallengeID:  v.ChallengeID,
+		Choice:       v.Choice,
+		SharePercent: share,
+	})
+	sort.SliceStable(stats.ContrarianPicks, func(i, j int) bool {
+		return stats.ContrarianPicks[i].SharePercent < stats.ContrarianPicks[j].SharePercent
+	})
+	if len(stats.ContrarianPicks) > maxContrarianPicks {
+		stats.ContrarianPicks = stats.ContrarianPicks[:maxContrarianPicks]
+	}
+}
+
+func (s *StatsService) toResponse(stats *models.UserStats) *dto.UserStatsResponse {
+	resp := &dto.UserStatsResponse{
+		TotalVotes:      stats.TotalVotes,
+		Categories:      make([]dto.CategoryStat, 0, len(stats.CategoryCounts)),
+		MajorityVotes:   stats.MajorityVotes,
+		DecidedVotes:    stats.DecidedVotes,
+		ContrarianPicks: []dto.ContrarianPickResponse{},
+		UpdatedAt:       stats.UpdatedAt,
+	}
+
+	var streak models.ChallengeStreak
+	if s.db.Where("user_id = ?", stats.UserID).First(&streak).Error == nil {
+		resp.CurrentStreak = streak.CurrentStreak
+		resp.LongestStreak = streak.LongestStreak
+	}
+
+	for category, votes := range stats.CategoryCounts {
+		resp.Categories = append(resp.Categories, dto.CategoryStat{
+			Category: category,
+			Votes:    votes,
+			Percent:  votes * 100 / max(stats.TotalVotes, 1),
+		})
+	}
+	sort.Slice(resp.Categories, func(i, j int) bool {
+		if resp.Categories[i].Votes != resp.Categories[j].Votes {
+			return resp.Categories[i].Votes > resp.Categories[j].Votes
+		}
+		return resp.Categories[i].Category < resp.Categories[j].Category
+	})
+	if len(resp.Categories) > 0 {
+		resp.FavoriteCategory = resp.Categories[0].Category
+	}
+
+	if stats.DecidedVotes > 0 {
+		resp.MainstreamScore = stats.MajorityVotes * 100 / stats.DecidedVotes
+	}
+	resp.Badge = personalityBadge(stats.DecidedVotes, resp.MainstreamScore)
+
+	if len(stats.ContrarianPicks) > 0 {
+		ids := make([]uuid.UUID, len(stats.ContrarianPicks))
+		for i, p := range stats.ContrarianPicks {
+			ids[i] = p.ChallengeID
+		}
+		var challenges []models.Challenge
+		s.db.Where("id IN ?", ids).Find(&challenges)
+		byID := make(map[uuid.UUID]*models.Challenge, len(challenges))
+		for i := range challenges {
+			byID[challenges[i].ID] = &challenges[i]
+		}
+
+		for _, p := range stats.ContrarianPicks {
+			ch, ok := byID[p.ChallengeID]
+			if !ok {
+				continue // Removed by moderation
+			}
+			resp.ContrarianPicks = append(resp.ContrarianPicks, dto.ContrarianPickResponse{
+				ChallengeID:  ch.ID,
+				OptionA:      ch.OptionA,
+				OptionB:      ch.OptionB,
+				Choice:       p.Choice,
+				SharePercent: p.SharePercent,
+			})
+		}
+	}
+
+	return resp
+}
+
+func personalityBadge(decided, mainstreamScore int) dto.PersonalityBadge {
+	switch {
+	case decided < minDecidedForBadge:
+		return personalityBadges["newcomer"]
+	case mainstreamScore >= 65:
+		return personalityBadges["crowd_pleaser"]
+	case mainstreamScore <= 35:
+		return personalityBadges["rebel"]
+	default:
+		return personalityBadges["wild_card"]
+	}
+}
@@ -1,21 +1,63 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
 	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var ErrEntitlementRequired = errors.New("entitlement required")
+
+// EntitlementError reports which entitlement the caller is missing.
+// It matches ErrEntitlementRequired with errors.Is.
+type EntitlementError struct {
+	Entitlement string
+}
+
+func (e *EntitlementError) Error() string {
+	return fmt.Sprintf("this content requires the %q entitlement", e.Entitlement)
+}
+
+func (e *EntitlementError) Is(target error) bool {
+	return target == ErrEntitlementRequired
+}
+
 type SubscriptionService struct {
-	db *gorm.DB
+	db                   *gorm.DB
+	premiumEntitlement   string
+	categoryEntitlements map[string]string
 }
 
-func NewSubscriptionService(db *gorm.DB) *SubscriptionService {
-	return &SubscriptionService{db: db}
+// NewSubscriptionService reads PREMIUM_CATEGORIES as a comma-separated list
+// of "category" or "category:entitlement"; bare categories are locked behind
+// the premium entitlement.
+func NewSubscriptionService(db *gorm.DB, cfg *config.Config) *SubscriptionService {
+	s := &SubscriptionService{
+		db:                   db,
+		premiumEntitlement:   cfg.PremiumEntitlementID,
+		categoryEntitlements: map[string]string{},
+	}
+
+	for _, entry := range strings.Split(cfg.PremiumCategories, ",") {
+		category, entitlement, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category == "" {
+			continue
+		}
+		if entitlement = strings.TrimSpace(entitlement); entitlement == "" {
+			entitlement = s.premiumEntitlement
+		}
+		s.categoryEntitlements[category] = entitlement
+	}
+
+	return s
 }
 
 func (s *SubscriptionService) HandleWebhookEvent(event *dto.RevenueCatEvent) error {
@@ -40,6 +82,7 @@ func (s *SubscriptionService) handleInitialPurchase(event *dto.RevenueCatEvent)
 		RevenueCatID:       event.AppUserID,
 		ProductID:          event.ProductID,
 		Status:             "active",
+		EntitlementIDs:     event.EntitlementIDs,
 		CurrentPeriodStart: msToTime(event.PurchasedAtMs),
 		CurrentPeriodEnd:   msToTime(event.ExpirationAtMs),
 	}
@@ -59,11 +102,20 @@ func (s *SubscriptionService) handleRenewal(event *dto.RevenueCatEvent) error {
 		return fmt.Errorf("subscription not found for renewal: %w", err)
 	}
 
-	return s.db.Model(&sub).Updates(map[string]interface{}{
-		"status":               "active",
-		"current_period_end":   msToTime(event.ExpirationAtMs),
-		"current_period_start": msToTime(event.PurchasedAtMs),
-	}).Error
+	update := models.Subscription{
+		Status:             "active",
+		CurrentPeriodEnd:   msToTime(event.ExpirationAtMs),
+		CurrentPeriodStart: msToTime(event.PurchasedAtMs),
+	}
+	columns := []string{"status", "current_period_end", "current_period_start"}
+
+	// Keep the stored entitlements if the event doesn't list any
+	if len(event.EntitlementIDs) > 0 {
+		update.EntitlementIDs = event.EntitlementIDs
+		columns = append(columns, "entitlement_ids")
+	}
+
+	return s.db.Model(&sub).Select(columns).Updates(&update).Error
 }
 
 func (s *SubscriptionService) handleCancellation(event *dto.RevenueCatEvent) error {
@@ -78,6 +130,121 @@ func (s *SubscriptionService) handleExpiration(event *dto.RevenueCatEvent) error
 		Update("status", "expired").Error
 }
 
+// ActiveEntitlements returns the entitlements granted by the user's
+// subscriptions whose paid period has not ended yet, plus unexpired grants
+// such as season prizes. Cancelled subscriptions keep their entitlements
+// until expiry. Subscriptions recorded without entitlement IDs grant the
+// premium entitlement.
+func (s *SubscriptionService) ActiveEntitlements(userID uuid.UUID) []string {
+	if userID == uuid.Nil {
+		return nil
+	}
+
+	var subs []models.Subscription
+	s.db.Where("(user_id = ? OR revenuecat_id = ?) AND status IN ? AND current_period_end > ?",
+		userID, userID.String(), []string{"active", "cancelled"}, time.Now()).
+		Find(&subs)
+
+	seen := map[string]bool{}
+	var entitlements []string
+	for _, sub := range subs {
+		ids := sub.EntitlementIDs
+		if len(ids) == 0 {
+			ids = []string{s.premiumEntitlement}
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				entitlements = append(entitlements, id)
+			}
+		}
+	}
+
+	var grants []models.EntitlementGrant
+	s.db.Where("user_id = ? AND expires_at > ?", userID, time.Now()).Find(&grants)
+	for _, g := range grants {
+		if !seen[g.Entitlement] {
+			seen[g.Entitlement] = true
+			entitlements = append(entitlements, g.Entitlement)
+		}
+	}
+	return entitlements
+}
+
+// GrantEntitlement gives the user an entitlement for duration outside the
+// app stores. A grant from the same source is renewed rather than repeated.
+func (s *SubscriptionService) GrantEntitlement(tx *gorm.DB, userID uuid.UUID, entitlement, source string, duration time.Duration) error {
+	var grant models.EntitlementGrant
+	err := tx.Where("user_id = ? AND entitlement = ? AND source = ?", userID, entitlement, source).First(&grant).Error
+	if err == nil {
+		return tx.Model(&grant).Update("expires_at", time.Now().Add(duration)).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return tx.Create(&models.EntitlementGrant{
+		UserID:      userID,
+		Entitlement: entitlement,
+		Source:      source,
+		ExpiresAt:   time.Now().Add(duration),
+	}).Error
+}
+
+// HasEntitlement reports whether the user currently holds entitlement.
+func (s *SubscriptionService) HasEntitlement(userID uuid.UUID, entitlement string) bool {
+	for _, id := range s.ActiveEntitlements(userID) {
+		if id == entitlement {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireEntitlement returns an *EntitlementError if the user lacks
+// entitlement, nil otherwise.
+func (s *SubscriptionService) RequireEntitlement(userID uuid.UUID, entitlement string) error {
+	if !s.HasEntitlement(userID, entitlement) {
+		return &EntitlementError{Entitlement: entitlement}
+	}
+	return nil
+}
+
+// IsPremium reports whether the user holds the premium entitlement.
+func (s *SubscriptionService) IsPremium(userID uuid.UUID) bool {
+	return s.HasEntitlement(userID, s.premiumEntitlement)
+}
+
+// PremiumEntitlement is the entitlement that unlocks premium packs.
+func (s *SubscriptionService) PremiumEntitlement() string {
+	return s.premiumEntitlement
+}
+
+// CategoryEntitlement returns the entitlement a category is locked behind,
+// or "" if the category is free.
+func (s *SubscriptionService) CategoryEntitlement(category string) string {
+	return s.categoryEntitlements[strings.ToLower(category)]
+}
+
+// LockedCategories returns the locked categories the user can't access.
+func (s *SubscriptionService) LockedCategories(userID uuid.UUID) []string {
+	if len(s.categoryEntitlements) == 0 {
+		return nil
+	}
+
+	held := map[string]bool{}
+	for _, id := range s.ActiveEntitlements(userID) {
+		held[id] = true
+	}
+
+	var locked []string
+	for category, entitlement := range s.categoryEntitlements {
+		if !held[entitlement] {
+			locked = append(locked, category)
+		}
+	}
+	return locked
+}
+
 func msToTime(ms int64) time.Time {
 	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
 }
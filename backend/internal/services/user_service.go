@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "golang.org/x/image/webp"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDisplayNameTaken    = errors.New("display name is already taken")
+	ErrInvalidDisplayName  = errors.New("display name must be 3-30 characters of letters, numbers, spaces, '.', '_' or '-'")
+	ErrDisplayNameFiltered = errors.New("display name contains prohibited terms")
+	ErrAvatarTooLarge      = errors.New("avatar must be 2 MB or smaller")
+	ErrAvatarType          = errors.New("avatar must be a JPEG, PNG or WebP image")
+)
+
+const (
+	MaxAvatarBytes     = 2 << 20
+	maxAvatarDimension = 4096
+	minDisplayName     = 3
+	maxDisplayName     = 30
+)
+
+var avatarExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+type UserService struct {
+	db                *gorm.DB
+	moderationService *ModerationService
+	storage           BlobStorage
+}
+
+func NewUserService(db *gorm.DB, moderation *ModerationService, storage BlobStorage) *UserService {
+	return &UserService{db: db, moderationService: moderation, storage: storage}
+}
+
+func (s *UserService) GetProfile(userID uuid.UUID) (*dto.UserProfileResponse, error) {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.toProfile(&user), nil
+}
+
+// UpdateProfile applies a partial profile update. Display names must pass
+// the content filter and be unique ignoring case.
+func (s *UserService) UpdateProfile(userID uuid.UUID, req *dto.UpdateProfileRequest) (*dto.UserProfileResponse, error) {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if req.DisplayName != nil {
+		name, key, err := s.normalizeDisplayName(*req.DisplayName)
+		if err != nil {
+			return nil, err
+		}
+
+		if key != nil {
+			var count int64
+			s.db.Unscoped().Model(&models.User{}).
+				Where("display_name_key = ? AND id <> ?", *key, userID).
+				Count(&count)
+			if count > 0 {
+				return nil, ErrDisplayNameTaken
+			}
+		}
+
+		if err := s.db.Model(&user).Updates(map[string]interface{}{
+			"display_name":     name,
+			"display_name_key": key,
+		}).Error; err != nil {
+			if isUniqueViolation(err) {
+				return nil, ErrDisplayNameTaken
+			}
+			return nil, err
+		}
+		user.DisplayName = name
+		user.DisplayNameKey = key
+	}
+
+	if req.ShowVotesToFriends != nil {
+		if err := s.db.Model(&user).Update("show_votes_to_friends", *req.ShowVotesToFriends).Error; err != nil {
+			return nil, err
+		}
+		user.ShowVotesToFriends = *req.ShowVotesToFriends
+	}
+
+	return s.toProfile(&user), nil
+}
+
+// SetAvatar validates and stores a new avatar, replacing the previous one.
+func (s *UserService) SetAvatar(userID uuid.UUID, data []byte) (*dto.UserProfileResponse, error) {
+	if len(data) > MaxAvatarBytes {
+		return nil, ErrAvatarTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := avatarExtensions[contentType]
+	if !ok {
+		return nil, ErrAvatarType
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width == 0 || cfg.Height == 0 || cfg.Width > maxAvatarDimension || cfg.Height > maxAvatarDimension {
+		return nil, ErrAvatarType
+	}
+
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("avatars/%s/%s%s", userID, hex.EncodeToString(suffix), ext)
+
+	if err := s.storage.Put(key, bytes.NewReader(data), contentType); err != nil {
+		return nil, fmt.Errorf("failed to store avatar: %w", err)
+	}
+	if err := s.db.Model(&user).Update("avatar_key", key).Error; err != nil {
+		s.storage.Delete(key)
+		return nil, err
+	}
+
+	if user.AvatarKey != "" {
+		s.storage.Delete(user.AvatarKey)
+	}
+	user.AvatarKey = key
+
+	return s.toProfile(&user), nil
+}
+
+// RemoveAvatar deletes the user's avatar, if any.
+func (s *UserService) RemoveAvatar(userID uuid.UUID) (*dto.UserProfileResponse, error) {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if user.AvatarKey != "" {
+		if err := s.db.Model(&user).Update("avatar_key", "").Error; err != nil {
+			return nil, err
+		}
+		s.storage.Delete(user.AvatarKey)
+		user.AvatarKey = ""
+	}
+
+	return s.toProfile(&user), nil
+}
+
+// AvatarURL returns the public URL of a user's avatar, or "" if unset.
+func (s *UserService) AvatarURL(user *models.User) string {
+	if user.AvatarKey == "" {
+		return ""
+	}
+	return s.storage.URL(user.AvatarKey)
+}
+
+func (s *UserService) toProfile(user *models.User) *dto.UserProfileResponse {
+	return &dto.UserProfileResponse{
+		ID:                 user.ID,
+		Email:              user.Email,
+		EmailVerified:      user.EmailVerifiedAt != nil,
+		DisplayName:        user.DisplayName,
+		AvatarURL:          s.AvatarURL(user),
+		ShowVotesToFriends: user.ShowVotesToFriends,
+		CreatedAt:          user.CreatedAt,
+	}
+}
+
+// normalizeDisplayName trims and validates a display name, returning it
+// with its uniqueness key. An empty name clears it (nil key).
+func (s *UserService) normalizeDisplayName(raw string) (string, *string, error) {
+	name := strings.Join(strings.Fields(raw), " ")
+	if name == "" {
+		return "", nil, nil
+	}
+
+	if n := utf8.RuneCountInString(name); n < minDisplayName || n > maxDisplayName {
+		return "", nil, ErrInvalidDisplayName
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" ._-", r) {
+			return "", nil, ErrInvalidDisplayName
+		}
+	}
+
+	if clean, _ := s.moderationService.FilterContent(name); !clean {
+		return "", nil, ErrDisplayNameFiltered
+	}
+
+	key := strings.ToLower(name)
+	return name, &key, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+)
+
+// .wypack is the portable, signed pack file used to ship packs outside the
+// API (bundled in the app, shared with partners, imported into staging).
+//
+// Layout, all integers big-endian:
+//
+//	magic      8 bytes   "WYPACK" 0x00 0x01 (format version 1)
+//	manifestN  uint32    length of manifest
+//	manifest   N bytes   JSON WypackManifest
+//	bodyN      uint32    length of body
+//	body       N bytes   gzip-compressed JSON []dto.PackChallenge
+//	signature  64 bytes  Ed25519 over every preceding byte
+//
+// The manifest carries the SHA-256 of the body, so verifying the signature
+// covers the challenges as well.
+var wypackMagic = []byte{'W', 'Y', 'P', 'A', 'C', 'K', 0x00, 0x01}
+
+const (
+	WypackFormatVersion = 1
+	WypackExtension     = ".wypack"
+	maxWypackSection    = 16 << 20
+)
+
+var (
+	ErrWypackMalformed    = errors.New("malformed .wypack file")
+	ErrWypackUntrustedKey = errors.New(".wypack is signed with an unknown key")
+	ErrWypackBadSignature = errors.New(".wypack signature verification failed")
+	ErrWypackUnsupported  = errors.New("unsupported .wypack format")
+)
+
+// WypackManifest describes the pack inside a .wypack file.
+type WypackManifest struct {
+	Format         int       `json:"format"`
+	PackID         string    `json:"pack_id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Category       string    `json:"category"`
+	IsPremium      bool      `json:"is_premium"`
+	Version        int       `json:"version"`
+	ChallengeCount int       `json:"challenge_count"`
+	ContentHash    string    `json:"content_hash"`
+	BodySHA256     string    `json:"body_sha256"`
+	KeyID          string    `json:"key_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PackSigner holds the server's Ed25519 pack key plus any extra public keys
+// whose packs we accept on import (e.g. production packs in staging).
+type PackSigner struct {
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+	keyID      string
+	trusted    map[string]ed25519.PublicKey
+}
+
+// NewPackSigner loads PACK_SIGNING_KEY (base64 32-byte seed or 64-byte
+// private key) and PACK_TRUSTED_KEYS (comma-separated base64 public keys).
+// The signing key is required outside development; there an ephemeral one
+// is generated instead, so exported files stop verifying after a restart.
+func NewPackSigner(cfg *config.Config) (*PackSigner, error) {
+	var priv ed25519.PrivateKey
+
+	if cfg.PackSigningKey == "" {
+		if !cfg.IsDevelopment() {
+			return nil, errors.New("PACK_SIGNING_KEY is required outside development (APP_ENV=development)")
+		}
+		log.Println("WARNING: PACK_SIGNING_KEY not set, using an ephemeral pack signing key")
+		_, generated, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate pack signing key: %w", err)
+		}
+		priv = generated
+	} else {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(cfg.PackSigningKey))
+		if err != nil {
+			return nil, fmt.Errorf("PACK_SIGNING_KEY is not valid base64: %w", err)
+		}
+		switch len(raw) {
+		case ed25519.SeedSize:
+			priv = ed25519.NewKeyFromSeed(raw)
+		case ed25519.PrivateKeySize:
+			priv = ed25519.PrivateKey(raw)
+		default:
+			return nil, fmt.Errorf("PACK_SIGNING_KEY must be a %d-byte seed or %d-byte private key", ed25519.SeedSize, ed25519.PrivateKeySize)
+		}
+	}
+
+	pub := priv.Public().(ed25519.PublicKey)
+	signer := &PackSigner{
+		privateKey: priv,
+		publicKey:  pub,
+		keyID:      packKeyID(pub),
+		trusted:    map[string]ed25519.PublicKey{},
+	}
+	signer.trusted[signer.keyID] = pub
+
+	for _, encoded := range strings.Split(cfg.PackTrustedKeys, ",") {
+		encoded = strings.TrimSpace(encoded)
+		if encoded == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("PACK_TRUSTED_KEYS contains an invalid Ed25519 public key: %q", encoded)
+		}
+		key := ed25519.PublicKey(raw)
+		signer.trusted[packKeyID(key)] = key
+	}
+
+	return signer, nil
+}
+
+// PublicKey returns the key clients use to verify cached .wypack files.
+func (s *PackSigner) PublicKey() dto.PackPublicKeyResponse {
+	return dto.PackPublicKeyResponse{
+		Algorithm: "Ed25519",
+		KeyID:     s.keyID,
+		PublicKey: base64.StdEncoding.EncodeToString(s.publicKey),
+		Format:    WypackFormatVersion,
+	}
+}
+
+// Encode serialises and signs a pack. manifest.KeyID, BodySHA256,
+// ChallengeCount and Format are filled in here.
+func (s *PackSigner) Encode(manifest WypackManifest, challenges []dto.PackChallenge) ([]byte, error) {
+	rawBody, err := json.Marshal(challenges)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&body, gzip.BestCompression)
+	if _, err := zw.Write(rawBody); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	bodySum := sha256.Sum256(body.Bytes())
+	manifest.Format = WypackFormatVersion
+	manifest.KeyID = s.keyID
+	manifest.ChallengeCount = len(challenges)
+	manifest.BodySHA256 = hex.EncodeToString(bodySum[:])
+
+	rawManifest, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.Write(wypackMagic)
+	binary.Write(&out, binary.BigEndian, uint32(len(rawManifest)))
+	out.Write(rawManifest)
+	binary.Write(&out, binary.BigEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+	out.Write(ed25519.Sign(s.privateKey, out.Bytes()))
+
+	return out.Bytes(), nil
+}
+
+// Decode verifies a .wypack against the trusted keys and returns its
+// manifest and challenges.
+func (s *PackSigner) Decode(data []byte) (*WypackManifest, []dto.PackChallenge, error) {
+	if len(data) < len(wypackMagic)+8+ed25519.SignatureSize || !bytes.Equal(data[:len(wypackMagic)], wypackMagic) {
+		return nil, nil, ErrWypackMalformed
+	}
+
+	signed := data[:len(data)-ed25519.SignatureSize]
+	signature := data[len(data)-ed25519.SignatureSize:]
+
+	r := bytes.NewReader(signed[len(wypackMagic):])
+	rawManifest, err := readWypackSection(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := readWypackSection(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.Len() != 0 {
+		return nil, nil, ErrWypackMalformed
+	}
+
+	var manifest WypackManifest
+	if err := json.Unmarshal(rawManifest, &manifest); err != nil {
+		return nil, nil, ErrWypackMalformed
+	}
+	if manifest.Format != WypackFormatVersion {
+		return nil, nil, fmt.Errorf("%w %d", ErrWypackUnsupported, manifest.Format)
+	}
+
+	key, ok := s.trusted[manifest.KeyID]
+	if !ok {
+		return nil, nil, ErrWypackUntrustedKey
+	}
+	if !ed25519.Verify(key, signed, signature) {
+		return nil, nil, ErrWypackBadSignature
+	}
+
+	bodySum := sha256.Sum256(body)
+	if hex.EncodeToString(bodySum[:]) != manifest.BodySHA256 {
+		return nil, nil, ErrWypackBadSignature
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, ErrWypackMalformed
+	}
+	rawBody, err := io.ReadAll(io.LimitReader(zr, maxWypackSection+1))
+	if err != nil || len(rawBody) > maxWypackSection {
+		return nil, nil, ErrWypackMalformed
+	}
+
+	var challenges []dto.PackChallenge
+	if err := json.Unmarshal(rawBody, &challenges); err != nil {
+		return nil, nil, ErrWypackMalformed
+	}
+	if len(challenges) != manifest.ChallengeCount {
+		return nil, nil, ErrWypackMalformed
+	}
+
+	return &manifest, challenges, nil
+}
+
+func readWypackSection(r *bytes.Reader) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, ErrWypackMalformed
+	}
+	if n > maxWypackSection || int(n) > r.Len() {
+		return nil, ErrWypackMalformed
+	}
+	section := make([]byte, n)
+	if _, err := io.ReadFull(r, section); err != nil {
+		return nil, ErrWypackMalformed
+	}
+	return section, nil
+}
+
+// packKeyID is a short, stable identifier for a public key.
+func packKeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/config"
+	"github.com/ahmetcoskunkizilkaya/wouldyou/backend/internal/dto"
+	"github.com/google/uuid"
+)
+
+func testPackSigner(t *testing.T, seed byte, trusted ...ed25519.PublicKey) *PackSigner {
+	t.Helper()
+	cfg := &config.Config{PackSigningKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{seed}, ed25519.SeedSize))}
+	for i, key := range trusted {
+		if i > 0 {
+			cfg.PackTrustedKeys += ","
+		}
+		cfg.PackTrustedKeys += base64.StdEncoding.EncodeToString(key)
+	}
+	signer, err := NewPackSigner(cfg)
+	if err != nil {
+		t.Fatalf("NewPackSigner: %v", err)
+	}
+	return signer
+}
+
+func testWypack(t *testing.T, signer *PackSigner) ([]byte, WypackManifest, []dto.PackChallenge) {
+	t.Helper()
+	manifest := WypackManifest{
+		PackID:    uuid.NewString(),
+		Name:      "Spicy",
+		Category:  "food",
+		Version:   3,
+		CreatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+	challenges := []dto.PackChallenge{
+		{ID: uuid.New(), OptionA: "Only eat pizza", OptionB: "Never eat pizza", Category: "food", SortOrder: 0},
+		{ID: uuid.New(), OptionA: "Cook every meal", OptionB: "Never cook", Category: "food", SortOrder: 1},
+	}
+	data, err := signer.Encode(manifest, challenges)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return data, manifest, challenges
+}
+
+func TestWypackRoundTrip(t *testing.T) {
+	signer := testPackSigner(t, 1)
+	data, want, challenges := testWypack(t, signer)
+
+	got, decoded, err := signer.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.PackID != want.PackID || got.Name != want.Name || got.Version != want.Version || !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("manifest = %+v, want fields of %+v", got, want)
+	}
+	if got.Format != WypackFormatVersion || got.KeyID != signer.keyID || got.ChallengeCount != len(challenges) {
+		t.Errorf("Encode did not fill format, key ID and count: %+v", got)
+	}
+	if !reflect.DeepEqual(decoded, challenges) {
+		t.Errorf("challenges = %+v, want %+v", decoded, challenges)
+	}
+}
+
+func TestNewPackSignerRequiresKeyOutsideDevelopment(t *testing.T) {
+	if _, err := NewPackSigner(&config.Config{AppEnv: "production"}); err == nil {
+		t.Error("NewPackSigner without a key in production: want error")
+	}
+	signer, err := NewPackSigner(&config.Config{AppEnv: "development"})
+	if err != nil {
+		t.Fatalf("NewPackSigner without a key in development: %v", err)
+	}
+	if len(signer.privateKey) != ed25519.PrivateKeySize {
+		t.Errorf("ephemeral key size = %d, want %d", len(signer.privateKey), ed25519.PrivateKeySize)
+	}
+}
+
+func TestWypackAcceptsTrustedKey(t *testing.T) {
+	partner := testPackSigner(t, 2)
+	signer := testPackSigner(t, 1, partner.publicKey)
+	data, _, _ := testWypack(t, partner)
+
+	if _, _, err := signer.Decode(data); err != nil {
+		t.Fatalf("Decode of a pack signed by a trusted key: %v", err)
+	}
+}
+
+func TestWypackRejectsTampering(t *testing.T) {
+	signer := testPackSigner(t, 1)
+	data, _, _ := testWypack(t, signer)
+
+	manifestLen := int(binary.BigEndian.Uint32(data[len(wypackMagic):]))
+	bodyStart := len(wypackMagic) + 4 + manifestLen + 4
+
+	tamper := func(edit func(b []byte) []byte) []byte {
+		return edit(append([]byte(nil), data...))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{
+			name: "manifest field edited",
+			data: tamper(func(b []byte) []byte {
+				return bytes.Replace(b, []byte(`"name":"Spicy"`), []byte(`"name":"Sp1cy"`), 1)
+			}),
+			want: ErrWypackBadSignature,
+		},
+		{
+			name: "body byte flipped",
+			data: tamper(func(b []byte) []byte { b[bodyStart+10] ^= 0xff; return b }),
+			want: ErrWypackBadSignature,
+		},
+		{
+			name: "signature byte flipped",
+			data: tamper(func(b []byte) []byte { b[len(b)-1] ^= 0xff; return b }),
+			want: ErrWypackBadSignature,
+		},
+		{
+			name: "bad magic",
+			data: tamper(func(b []byte) []byte { b[0] = 'X'; return b }),
+			want: ErrWypackMalformed,
+		},
+		{
+			name: "truncated",
+			data: tamper(func(b []byte) []byte { return b[:bodyStart] }),
+			want: ErrWypackMalformed,
+		},
+		{
+			name: "trailing bytes before signature",
+			data: tamper(func(b []byte) []byte {
+				sig := append([]byte(nil), b[len(b)-ed25519.SignatureSize:]...)
+				return append(append(b[:len(b)-ed25519.SignatureSize], 0), sig...)
+			}),
+			want: ErrWypackMalformed,
+		},
+		{
+			name: "signed by an unknown key",
+			data: func() []byte { d, _, _ := testWypack(t, testPackSigner(t, 9)); return d }(),
+			want: ErrWypackUntrustedKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := signer.Decode(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("Decode error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}